@@ -0,0 +1,55 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+// NoiseBatch writes the noise for the point (xs[i], ys[i]) into out[i] for
+// every index of out. If the Noiser is also a BatchNoiser its NoiseBatch
+// method is used, otherwise Noise is called for each point. The xs and ys
+// slices must be at least as long as out.
+func NoiseBatch(n Noiser, xs, ys, out []float64) {
+	if b, ok := n.(BatchNoiser); ok {
+		b.NoiseBatch(xs, ys, out)
+		return
+	}
+	xs = xs[:len(out)]
+	ys = ys[:len(out)]
+	for i := range out {
+		out[i] = n.Noise(xs[i], ys[i])
+	}
+}
+
+// FillGrid samples a Noiser on a regular grid. The dst slice holds rows of
+// stride samples each, and len(dst)/stride rows are filled. The sample at
+// dst[row*stride+col] is taken at (minX+col*dx, minY+row*dy). If the Noiser is
+// also a GridNoiser its FillGrid method is used, otherwise Noise is called for
+// each sample.
+func FillGrid(n Noiser, dst []float64, stride int, minX, minY, dx, dy float64) {
+	if g, ok := n.(GridNoiser); ok {
+		g.FillGrid(dst, stride, minX, minY, dx, dy)
+		return
+	}
+	if stride <= 0 {
+		return
+	}
+	for row := 0; row < len(dst)/stride; row++ {
+		noiseY := minY + float64(float64(row)*dy)
+		for col := 0; col < stride; col++ {
+			dst[row*stride+col] = n.Noise(minX+float64(float64(col)*dx), noiseY)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"testing"
+)
+
+// batchPoints returns the coordinates of a regular grid of samples, in the
+// same order WriteGreyImagePng samples them.
+func batchPoints(dimension int) (xs, ys []float64) {
+	xs = make([]float64, 0, dimension*dimension)
+	ys = make([]float64, 0, dimension*dimension)
+	for y := 0; y < dimension; y++ {
+		for x := 0; x < dimension; x++ {
+			xs = append(xs, startCorner+float64(x)*sampleStep)
+			ys = append(ys, startCorner+float64(y)*sampleStep)
+		}
+	}
+	return
+}
+
+func pinkOctave(n Noiser) *OctaveNoise {
+	o := NewOctaveNoise(0.5)
+	for i := 0; i < 8; i++ {
+		o.AddOctave(n)
+	}
+	return o
+}
+
+func testBatchMatchesNoise(t *testing.T, n Noiser) {
+	xs, ys := batchPoints(imageDimension)
+	out := make([]float64, len(xs))
+	NoiseBatch(n, xs, ys, out)
+	for i := range out {
+		if want := n.Noise(xs[i], ys[i]); out[i] != want {
+			t.Fatalf("NoiseBatch(%v, %v) = %v, want %v", xs[i], ys[i], out[i], want)
+		}
+	}
+}
+
+func TestPerlinBatch(t *testing.T) {
+	testBatchMatchesNoise(t, NewPerlin(seed))
+}
+
+func TestSimplexBatch(t *testing.T) {
+	testBatchMatchesNoise(t, NewSimplex(seed))
+}
+
+func TestOctaveBatchReusesMemory(t *testing.T) {
+	octave := pinkOctave(NewPerlin(seed))
+	xs, ys := batchPoints(16)
+	out := make([]float64, len(xs))
+	allocs := testing.AllocsPerRun(100, func() {
+		octave.NoiseBatch(xs, ys, out)
+	})
+	// A garbage collection may empty the pool during a run.
+	if allocs > 0.1 {
+		t.Errorf("got %v allocations per batch, want 0", allocs)
+	}
+}
+
+func TestPinkPerlinOctaveBatch(t *testing.T) {
+	testBatchMatchesNoise(t, pinkOctave(NewPerlin(seed)))
+}
+
+func TestFallbackBatch(t *testing.T) {
+	testBatchMatchesNoise(t, pinkOctave(NewPerlinCatmullRom(splineCacheSize, seed)))
+}
+
+func benchmarkPerPoint(b *testing.B, n Noiser) {
+	xs, ys := batchPoints(imageDimension)
+	out := make([]float64, len(xs))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range out {
+			out[j] = n.Noise(xs[j], ys[j])
+		}
+	}
+}
+
+func benchmarkBatch(b *testing.B, n Noiser) {
+	xs, ys := batchPoints(imageDimension)
+	out := make([]float64, len(xs))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NoiseBatch(n, xs, ys, out)
+	}
+}
+
+func BenchmarkPerlinPerPoint(b *testing.B) {
+	benchmarkPerPoint(b, NewPerlin(seed))
+}
+
+func BenchmarkPerlinBatch(b *testing.B) {
+	benchmarkBatch(b, NewPerlin(seed))
+}
+
+func BenchmarkSimplexPerPoint(b *testing.B) {
+	benchmarkPerPoint(b, NewSimplex(seed))
+}
+
+func BenchmarkSimplexBatch(b *testing.B) {
+	benchmarkBatch(b, NewSimplex(seed))
+}
+
+func BenchmarkPinkPerlinOctavePerPoint(b *testing.B) {
+	benchmarkPerPoint(b, pinkOctave(NewPerlin(seed)))
+}
+
+func BenchmarkPinkPerlinOctaveBatch(b *testing.B) {
+	benchmarkBatch(b, pinkOctave(NewPerlin(seed)))
+}
@@ -32,7 +32,14 @@ type catmullRom struct {
 // 0.5, then the spline is a centripetal one. If alpha is 1.0, then the spline
 // is a chordal one. Finally, if alpha is 0, the spline is a uniform one.
 func newCatmullRom(alpha float64, p0, p1, p2, p3 point2D) *catmullRom {
-	c := &catmullRom{
+	c := makeCatmullRom(alpha, p0, p1, p2, p3)
+	return &c
+}
+
+// makeCatmullRom creates a generic Catmull-Rom spline as a value, so that
+// short-lived splines need not be allocated on the heap.
+func makeCatmullRom(alpha float64, p0, p1, p2, p3 point2D) catmullRom {
+	c := catmullRom{
 		alpha: alpha,
 		p0:    p0,
 		p1:    p1,
@@ -93,6 +100,57 @@ func (c *catmullRom) At(t float64) point2D {
 	return b1.Scale(cLower).Add(b2.Scale(cUpper))
 }
 
+// SampledInterpolateX estimates the y-value for the given x-value the same
+// way as a catmullRomCached with nPoints points, but evaluates only the
+// samples visited by the binary search instead of storing all of them.
+func (c *catmullRom) SampledInterpolateX(nPoints int, x float64) float64 {
+	if nPoints <= 2 {
+		nPoints = 3
+	}
+	delta := (c.UpperT() - c.LowerT()) / float64(nPoints-1)
+	n := 0
+	for t := c.LowerT(); t <= c.UpperT(); t += delta {
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	min := 0
+	max := n - 1
+	i := (max + min) / 2
+	for min <= max && i > 0 && i < n-1 {
+		lower := c.sample(delta, i)
+		upper := c.sample(delta, i+1)
+		if lower.X <= x && upper.X > x {
+			break
+		} else if lower.X > x {
+			max = i - 1
+		} else if upper.X <= x {
+			min = i + 1
+		} else {
+			break
+		}
+		i = (max + min) / 2
+	}
+	if i >= n-1 {
+		return c.sample(delta, n-1).Y
+	} else if i < 0 {
+		return c.sample(delta, 0).Y
+	}
+	return c.sample(delta, i).LinearInterpolation(c.sample(delta, i+1), x)
+}
+
+// sample returns the i-th point of a catmullRomCached whose samples are delta
+// apart. The parametric value is accumulated the same way as the cache does
+// so that the points are identical.
+func (c *catmullRom) sample(delta float64, i int) point2D {
+	t := c.LowerT()
+	for ; i > 0; i-- {
+		t += delta
+	}
+	return c.At(t)
+}
+
 // lowerUpper calculates the coefficients necessary for generating a
 // Catmull-Rom spline from parametric values.
 func (c *catmullRom) lowerUpper(tLower, tUpper, t float64) (lower float64, upper float64) {
@@ -105,8 +163,8 @@ func (c *catmullRom) lowerUpper(tLower, tUpper, t float64) (lower float64, upper
 // points.
 func (c *catmullRom) nextT(p0, p1 point2D) float64 {
 	dx := p1.X - p0.X
-	dx2 := dx * dx
+	dx2 := float64(dx * dx)
 	dy := p1.Y - p0.Y
-	dy2 := dy * dy
+	dy2 := float64(dy * dy)
 	return math.Pow(math.Sqrt(dx2+dy2), c.alpha)
 }
@@ -0,0 +1,240 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// ColorSpace is the space in which a ColorRamp blends the colors of its stops.
+type ColorSpace int
+
+const (
+	// RGBSpace blends the red, green and blue values of the colors directly.
+	RGBSpace ColorSpace = iota
+	// OklabSpace blends colors in the Oklab perceptual color space, which
+	// keeps the lightness of the blend even and avoids the dull midpoints of
+	// RGB blends.
+	OklabSpace
+)
+
+// ColorStop is a color at a position from zero to one along a ColorRamp.
+type ColorStop struct {
+	Position float64
+	Color    color.NRGBA
+}
+
+// ColorRamp maps normalized noise values from zero to one onto colors, by
+// blending the two stops on either side of the value. Values before the first
+// stop or after the last have the color of that stop.
+type ColorRamp struct {
+	stops []ColorStop
+	space ColorSpace
+}
+
+// NewColorRamp creates a ColorRamp blending in the color space. It needs at
+// least one stop, and the stops are sorted by position.
+func NewColorRamp(space ColorSpace, stops ...ColorStop) (*ColorRamp, error) {
+	if len(stops) == 0 {
+		return nil, errors.New("color ramp needs at least one stop")
+	}
+	if space != RGBSpace && space != OklabSpace {
+		return nil, errors.New("unknown color space")
+	}
+	r := &ColorRamp{
+		stops: append([]ColorStop(nil), stops...),
+		space: space,
+	}
+	sort.SliceStable(r.stops, func(i, j int) bool {
+		return r.stops[i].Position < r.stops[j].Position
+	})
+	return r, nil
+}
+
+// GrayscaleRamp returns a ramp from black to white.
+func GrayscaleRamp() *ColorRamp {
+	return &ColorRamp{
+		stops: []ColorStop{
+			{0, color.NRGBA{0, 0, 0, 255}},
+			{1, color.NRGBA{255, 255, 255, 255}},
+		},
+		space: RGBSpace,
+	}
+}
+
+// TerrainRamp returns a ramp from deep water through beaches, grassland and
+// rock to snow, with the coast at one half.
+func TerrainRamp() *ColorRamp {
+	return &ColorRamp{
+		stops: []ColorStop{
+			{0, color.NRGBA{0, 0, 96, 255}},
+			{0.45, color.NRGBA{32, 96, 192, 255}},
+			{0.5, color.NRGBA{224, 208, 144, 255}},
+			{0.55, color.NRGBA{64, 160, 48, 255}},
+			{0.75, color.NRGBA{32, 96, 32, 255}},
+			{0.88, color.NRGBA{128, 112, 96, 255}},
+			{1, color.NRGBA{255, 255, 255, 255}},
+		},
+		space: RGBSpace,
+	}
+}
+
+// HeatRamp returns a ramp from black through red and yellow to white.
+func HeatRamp() *ColorRamp {
+	return &ColorRamp{
+		stops: []ColorStop{
+			{0, color.NRGBA{0, 0, 0, 255}},
+			{0.35, color.NRGBA{192, 0, 0, 255}},
+			{0.7, color.NRGBA{255, 208, 0, 255}},
+			{1, color.NRGBA{255, 255, 255, 255}},
+		},
+		space: OklabSpace,
+	}
+}
+
+// OceanRamp returns a ramp from the dark blue of deep water to the pale cyan
+// of shallows.
+func OceanRamp() *ColorRamp {
+	return &ColorRamp{
+		stops: []ColorStop{
+			{0, color.NRGBA{0, 8, 48, 255}},
+			{0.6, color.NRGBA{0, 96, 160, 255}},
+			{1, color.NRGBA{160, 240, 240, 255}},
+		},
+		space: OklabSpace,
+	}
+}
+
+// AspectRamp returns a color wheel from red through yellow, green, cyan, blue
+// and magenta back to red, whose ends match so that directions turning past
+// north blend without a seam.
+func AspectRamp() *ColorRamp {
+	return &ColorRamp{
+		stops: []ColorStop{
+			{0, color.NRGBA{255, 0, 0, 255}},
+			{1.0 / 6, color.NRGBA{255, 255, 0, 255}},
+			{2.0 / 6, color.NRGBA{0, 255, 0, 255}},
+			{3.0 / 6, color.NRGBA{0, 255, 255, 255}},
+			{4.0 / 6, color.NRGBA{0, 0, 255, 255}},
+			{5.0 / 6, color.NRGBA{255, 0, 255, 255}},
+			{1, color.NRGBA{255, 0, 0, 255}},
+		},
+		space: RGBSpace,
+	}
+}
+
+// At returns the color of the ramp at the normalized value.
+func (r *ColorRamp) At(t float64) color.NRGBA {
+	i := sort.Search(len(r.stops), func(i int) bool {
+		return r.stops[i].Position > t
+	})
+	if i == 0 {
+		return r.stops[0].Color
+	}
+	if i == len(r.stops) {
+		return r.stops[i-1].Color
+	}
+	lower, upper := r.stops[i-1], r.stops[i]
+	frac := (t - lower.Position) / (upper.Position - lower.Position)
+	if r.space == OklabSpace {
+		return blendOklab(lower.Color, upper.Color, frac)
+	}
+	return blendRGB(lower.Color, upper.Color, frac)
+}
+
+// blendRGB linearly interpolates each channel of the colors.
+func blendRGB(c0, c1 color.NRGBA, t float64) color.NRGBA {
+	return color.NRGBA{
+		R: blendChannel(c0.R, c1.R, t),
+		G: blendChannel(c0.G, c1.G, t),
+		B: blendChannel(c0.B, c1.B, t),
+		A: blendChannel(c0.A, c1.A, t),
+	}
+}
+
+// blendChannel linearly interpolates an 8-bit channel, rounding to nearest.
+func blendChannel(a, b uint8, t float64) uint8 {
+	return uint8(math.Round(linearInterpolation(float64(a), float64(b), t)))
+}
+
+// blendOklab interpolates the colors in the Oklab color space. Alpha is
+// interpolated linearly.
+func blendOklab(c0, c1 color.NRGBA, t float64) color.NRGBA {
+	l0, a0, b0 := toOklab(c0)
+	l1, a1, b1 := toOklab(c1)
+	r, g, b := fromOklab(
+		linearInterpolation(l0, l1, t),
+		linearInterpolation(a0, a1, t),
+		linearInterpolation(b0, b1, t),
+	)
+	return color.NRGBA{R: r, G: g, B: b, A: blendChannel(c0.A, c1.A, t)}
+}
+
+// toOklab converts an sRGB color to Oklab lightness and chroma coordinates.
+func toOklab(c color.NRGBA) (l, a, b float64) {
+	r := srgbToLinear(c.R)
+	g := srgbToLinear(c.G)
+	bl := srgbToLinear(c.B)
+
+	lc := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl)
+	mc := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl)
+	sc := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl)
+
+	l = 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc
+	a = 1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc
+	b = 0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+	return
+}
+
+// fromOklab converts Oklab coordinates to an sRGB color, clipping colors
+// outside the sRGB gamut.
+func fromOklab(l, a, b float64) (r, g, bl uint8) {
+	lc := l + 0.3963377774*a + 0.2158037573*b
+	mc := l - 0.1055613458*a - 0.0638541728*b
+	sc := l - 0.0894841775*a - 1.2914855480*b
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+
+	r = linearToSrgb(4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc)
+	g = linearToSrgb(-1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc)
+	bl = linearToSrgb(-0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc)
+	return
+}
+
+// srgbToLinear converts an sRGB channel to linear light from zero to one.
+func srgbToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSrgb converts linear light to an sRGB channel, clipping it to the
+// range of the channel.
+func linearToSrgb(v float64) uint8 {
+	v = clampUnit(v)
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(clampUnit(v) * 255))
+}
@@ -0,0 +1,131 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestColorRampRGB(t *testing.T) {
+	r, err := NewColorRamp(RGBSpace,
+		ColorStop{1, color.NRGBA{255, 0, 0, 255}},
+		ColorStop{0, color.NRGBA{0, 0, 255, 0}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		t    float64
+		want color.NRGBA
+	}{
+		{-1, color.NRGBA{0, 0, 255, 0}},
+		{0, color.NRGBA{0, 0, 255, 0}},
+		{0.5, color.NRGBA{128, 0, 128, 128}},
+		{1, color.NRGBA{255, 0, 0, 255}},
+		{2, color.NRGBA{255, 0, 0, 255}},
+	}
+	for _, test := range tests {
+		if got := r.At(test.t); got != test.want {
+			t.Errorf("At(%v) = %v, want %v", test.t, got, test.want)
+		}
+	}
+}
+
+func TestColorRampOklab(t *testing.T) {
+	for _, c := range []color.NRGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {12, 200, 99, 255}, {255, 0, 0, 255}} {
+		r, g, b := fromOklab(toOklab(c))
+		if (color.NRGBA{r, g, b, 255}) != c {
+			t.Errorf("%v converts back to %v, %v, %v", c, r, g, b)
+		}
+	}
+
+	ramp, err := NewColorRamp(OklabSpace,
+		ColorStop{0, color.NRGBA{0, 0, 0, 255}},
+		ColorStop{1, color.NRGBA{255, 255, 255, 255}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mid := ramp.At(0.5)
+	if mid.R != mid.G || mid.G != mid.B {
+		t.Errorf("Oklab blend of black and white is not grey: %v", mid)
+	}
+	// Oklab is perceptually even, so its middle grey is darker in sRGB
+	// than the middle of the channel values.
+	if mid.R >= 128 {
+		t.Errorf("Oklab middle grey is %v, want darker than the RGB blend", mid)
+	}
+}
+
+func TestColorRampPresets(t *testing.T) {
+	presets := map[string]*ColorRamp{
+		"grayscale": GrayscaleRamp(),
+		"terrain":   TerrainRamp(),
+		"heat":      HeatRamp(),
+		"ocean":     OceanRamp(),
+		"aspect":    AspectRamp(),
+	}
+	for name, ramp := range presets {
+		for i := 1; i < len(ramp.stops); i++ {
+			if ramp.stops[i-1].Position >= ramp.stops[i].Position {
+				t.Errorf("%s stops are not sorted", name)
+			}
+		}
+		if ramp.At(0) != ramp.stops[0].Color || ramp.At(1) != ramp.stops[len(ramp.stops)-1].Color {
+			t.Errorf("%s does not span its stops", name)
+		}
+	}
+	if GrayscaleRamp().At(0.5) != (color.NRGBA{128, 128, 128, 255}) {
+		t.Error("grayscale ramp is not linear")
+	}
+	if AspectRamp().At(0) != AspectRamp().At(1) {
+		t.Error("aspect ramp ends do not match")
+	}
+}
+
+func TestNewColorRampErrors(t *testing.T) {
+	if _, err := NewColorRamp(RGBSpace); err == nil {
+		t.Error("no error for a ramp without stops")
+	}
+	if _, err := NewColorRamp(ColorSpace(7), ColorStop{}); err == nil {
+		t.Error("no error for an unknown color space")
+	}
+}
+
+func TestWriteColorImagePng(t *testing.T) {
+	// A nil ramp is grayscale.
+	for _, ramp := range []*ColorRamp{GrayscaleRamp(), nil} {
+		var buffer bytes.Buffer
+		if err := WriteColorImagePng(context.Background(), &buffer, rowNoise{}, 0, 1, 2, 3, 1, 0, &FixedRange{Min: 1, Max: 3}, ramp); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := png.Decode(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range []uint8{0, 128, 255} {
+			if got := color.NRGBAModel.Convert(decoded.At(1, 2-i)); got != (color.NRGBA{want, want, want, 255}) {
+				t.Errorf("row %d color = %v, want grey %d", i, got, want)
+			}
+		}
+	}
+}
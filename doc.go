@@ -20,7 +20,13 @@
 	implementations for Perlin and Simplex noise in two dimensions. All
 	noises use a seed and a lookup table to create consistent outputs for
 	the same seed, even from different noise generators. All noises
-	implement the Noiser interface.
+	implement the Noiser interface. Noises that can sample many points
+	faster than one at a time also implement BatchNoiser, and the
+	NoiseBatch function samples any Noiser in bulk.
+
+	Perlin32 and Simplex32 compute the same noise in single precision for
+	pipelines that store float32 values, and implement Noiser32. Their
+	values are within Float32Tolerance of the double precision versions.
 
 	Octave noise is also provided, although must be composed of other
 	noises to produce the resulting smoothed noise.
@@ -33,15 +39,37 @@
 	betray the regular rectangular pattern of the gradient used to
 	interpolate, while the latter can elminate these artifacts.
 	Furthermore, the noise sampled at coordinate points will all be zero.
-	It is knowwn that the Catmull-Rom spline interpolation method contains
-	gridlike artifacts.
+	The centripetal splines of NewPerlinCatmullRom contain gridlike
+	artifacts. NewSmoothPerlinCatmullRom uses bicubic uniform splines
+	instead, whose value and first derivatives are continuous across the
+	lattice cells.
 
 		// Perlin noise with 4-point interpolation
 		perlinGenerator := noise.NewPerlin(1)
 		val := perlinGenerator.Noise(0.5, 0.5)
 		// Perlin noise with 16-point interpolation
-		catmullRomPerlinGenerator := noise.NewPerlinCatmullRom(1)
+		catmullRomPerlinGenerator := noise.NewPerlinCatmullRom(2, 1)
 		val := catmullRomPerlinGenerator.Noise(0.5, 0.5)
+		// Perlin noise with smooth 16-point interpolation
+		smoothPerlinGenerator := noise.NewSmoothPerlinCatmullRom(1)
+		val := smoothPerlinGenerator.Noise(0.5, 0.5)
+
+	The fading function of the 4-point interpolation can be changed to
+	trade speed against smoothness, or to match older engines that used the
+	cubic curve. The 16-point interpolation can use other cubic
+	interpolators, such as B-splines.
+
+		// Perlin noise with the original cubic fading function
+		cubicFadeGenerator := noise.NewPerlinWithFade(1, noise.CubicFade)
+		// Perlin noise with 16-point B-spline interpolation
+		bSplineGenerator := noise.NewPerlinCubic(1, noise.BSplineInterpolator)
+
+	The lattice gradients and the size of the lookup table can also be
+	chosen. More gradient directions reduce directional bias, and smaller
+	tables use less memory but repeat the noise sooner.
+
+		// Perlin noise with 256 gradient directions repeating every 1024 units
+		gradientGenerator := noise.NewPerlinWithGradients(1, 1024, noise.EvenGradients(256), nil)
 
 	Simplex noise uses simplexes to efficiently interpolate noise instead
 	of a regular rectangular grid. This can result in a different skewed
@@ -61,11 +89,159 @@
 		// Pink noise with two octaves.
 		pinkNoiseGenerator := noise.NewOctaveNoise(0.5)
 		pinkNoiseGenerator.AddOctave(noise.NewPerlin(1))
-		pinkNoiseGenerator.AddOctave(noise.NewPerlin(1))
+		pinkNoiseGenerator.AddOctave(noise.NewPerlin(2))
 		val := pinkNoiseGenerator.Noise(0.5, 0.5)
 
+	Octaves with the same seed are correlated, because the lattice of each
+	octave lines up with the next. Seeds derived from a single Seed keep
+	each octave and layer independent but reproducible.
+
+		// Pink noise with eight decorrelated octaves.
+		newPerlin := func(seed int64) noise.Noiser { return noise.NewPerlin(seed) }
+		decorrelatedGenerator := noise.NewSeededOctaveNoise(0.5, 8, noise.Seed(1), newPerlin)
+		// Independent seeds for other layers of a world.
+		moistureGenerator := noise.NewSimplex(noise.Seed(1).Derive("moisture", 0).Int64())
+
+	Each generator constructed from a seed builds its own permutation
+	table. Generators can instead share one immutable PermutationTable, so
+	that memory grows with the number of distinct seeds rather than the
+	number of generators.
+
+		// Eight Perlin noises sharing a single table.
+		table := noise.NewPermutationTable(1, noise.DefaultTableSize)
+		sharedGenerator := noise.NewOctaveNoise(0.5)
+		for i := 0; i < 8; i++ {
+			sharedGenerator.AddOctave(noise.NewPerlinFromTable(table, nil, nil))
+		}
+
+	The tables of generators constructed from a seed are shuffled with
+	math/rand, whose streams are not guaranteed to stay the same across Go
+	releases. Worlds that must never change should build their tables with
+	NewStablePermutationTable, whose SplitMix64 stream is defined by this
+	package. Every product in the float64 generators that feeds an addition
+	or subtraction is rounded with an explicit conversion, so the compiler
+	cannot fuse them into FMA instructions on architectures such as arm64,
+	and golden tests pin the exact values of both kinds of table.
+
+		// Perlin noise that is the same on every platform and Go release.
+		stableTable := noise.NewStablePermutationTable(1, noise.DefaultTableSize)
+		stableGenerator := noise.NewPerlinFromTable(stableTable, nil, nil)
+
+	World seeds typed as text are hashed into a Seed, which can be passed
+	to any constructor. The hash is FNV-1a, so the same text gives the same
+	world everywhere.
+
+		// Perlin noise seeded by a player's text.
+		worldSeed := noise.SeedFromString("Glacier Bay")
+		textGenerator := noise.NewPerlinFromTable(noise.NewStablePermutationTable(worldSeed.Int64(), noise.DefaultTableSize), nil, nil)
+
+	Bug fixes to the algorithms are released as a new Version. The
+	versioned constructors freeze the noise at a version, so that old
+	saves reproduce with Version1 while new worlds use LatestVersion.
+
+		// Perlin noise as generated by the first release.
+		v1Generator, err := noise.NewPerlinVersion(1, noise.Version1)
+
+	Hashed generators need no permutation table at all. They hash each
+	lattice point together with the seed, so their noise never repeats and
+	every 64-bit seed gives different noise.
+
+		// Perlin noise without a permutation table
+		hashedGenerator := noise.NewPerlinHashed(1)
+
+	Worley noise, also known as cellular noise, measures the distance to
+	the nearest of a set of hashed feature points, one per lattice cell.
+
+		// Cellular noise
+		worleyGenerator := noise.NewWorley(1)
+
+	Noise graphs can also be built at runtime from a small expression
+	language, which is useful for accepting formulas typed by a user.
+	Parse errors report the line and column of the problem.
+
+		// Pink noise scaled down and mixed with cellular noise.
+		exprGenerator, err := noise.ParseExpression("fbm(perlin(1), octaves=2) * 0.5 + worley(1)")
+
 	A utility function is provided to help write out Noisers to greyscale
 	PNG images. It uses goroutines to parallelize sampling due to the
-	slowness of some noise generation methods.
+	slowness of some noise generation methods. WriteGreyImagePngContext
+	limits the number of goroutines and stops when its context is done.
+
+		// A large image that gives up after a minute, using four workers.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err := noise.WriteGreyImagePngContext(ctx, w, generator, 0, 0, 16384, 16384, 0.01, 4, noise.LatestVersion)
+
+	SampleStats returns the minimum, maximum, mean, variance and a
+	histogram of noise over a region, which is useful to check the range
+	of a noise graph before normalizing it.
+
+		// Statistics with a ten bin histogram.
+		stats, err := noise.SampleStats(generator, 0, 0, 512, 512, 0.01, 10)
+
+	Images normally stretch their own samples to full greyscale, so
+	neighbouring tiles do not match. A Normalizer shared by every tile,
+	such as a FixedRange or a range between two percentiles, maps each
+	value to the same shade in every tile.
+
+		// Tiles that all map -1 to black and 1 to white.
+		err := noise.WriteGreyImagePngNormalized(ctx, w, generator, tileX, tileY, 256, 256, 0.01, 0, &noise.FixedRange{Min: -1, Max: 1})
+
+	Color images map the normalized noise through a ColorRamp of color
+	stops, blended in RGB or in the perceptual Oklab space. Terrain, heat,
+	ocean and grayscale ramps are provided.
+
+		// A map preview colored from water to snow.
+		err := noise.WriteColorImagePng(ctx, w, generator, 0, 0, 512, 512, 0.01, 0, nil, noise.TerrainRamp())
+
+	Up to four noises can be packed into the channels of a 16-bit RGBA
+	image, each normalized on its own, for mask textures.
+
+		// Perlin noise in red and simplex noise in green.
+		channels := []noise.Channel{{Noiser: generator}, {Noiser: simplexGenerator}}
+		err := noise.WriteRGBAImagePng(ctx, w, channels, 0, 0, 512, 512, 0.01, 0)
+
+	NoiseImage implements image.Image by sampling the noise as each pixel
+	is read, so noise can be given to image/draw or any encoder without
+	buffering its samples.
+
+		// Encode noise as a JPEG directly.
+		err := jpeg.Encode(w, noise.NewNoiseImage(generator, 0, 0, 512, 512, 0.01, nil), nil)
+
+	Heightmaps for terrain engines can be written without losing precision
+	as raw float32 or float64 grids or as portable float maps, or as the
+	16-bit RAW heightmaps imported by Unity and Unreal. ASCII PGM and PPM
+	files are also supported.
+
+		// A lossless heightmap.
+		err := noise.WritePFM(w, generator, 0, 0, 1025, 1025, 0.01)
+
+	Noises that implement GradientNoiser, such as Perlin, Simplex and
+	OctaveNoise, compute their slopes exactly. Perlin does so with every
+	Fade of this package, and with custom fades created by NewFade with a
+	derivative. NoiseGradient estimates the slopes of any other Noiser with
+	finite differences. The slopes are used to write tangent-space normal
+	maps.
+
+		// A normal map with the bumps doubled in height.
+		err := noise.WriteNormalMapPng(ctx, w, generator, 0, 0, 512, 512, 0.01, 0, 2)
+
+	Terrain previews can be rendered as shaded relief lit by a sun, or as
+	the steepness or direction of the slopes. A Relief sets the lighting
+	and an optional color ramp. Directions go around AspectRamp, a color
+	wheel, unless the Relief has a ramp of its own.
+
+		// Shaded relief colored from water to snow.
+		relief := noise.DefaultRelief()
+		relief.Ramp = noise.TerrainRamp()
+		err := noise.WriteHillshadePng(ctx, w, generator, 0, 0, 512, 512, 0.01, 0, relief)
+
+	Terrain meshes can be exported as Wavefront OBJ, binary STL or PLY,
+	with optional normals and texture coordinates. A skirt closes the mesh
+	into a solid that can be 3D printed.
+
+		// A printable terrain with the heights exaggerated tenfold.
+		meshOptions := &noise.MeshOptions{VerticalScale: 10, SkirtDepth: 2}
+		err := noise.WriteSTL(ctx, w, generator, 0, 0, 256, 256, 0.05, 0, meshOptions)
 */
 package noise
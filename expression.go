@@ -0,0 +1,612 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+)
+
+// ExpressionError describes a problem found while parsing a noise expression.
+// Line and Column are one-based and point at the offending token.
+type ExpressionError struct {
+	Line, Column int
+	Msg          string
+}
+
+// Error formats the error with its position.
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParseExpression compiles a noise expression into a Noiser. Expressions
+// combine numbers and generator calls with the operators +, -, * and /, and
+// parentheses for grouping. Call arguments may be positional or named, but
+// positional arguments must come first:
+//
+//	fbm(perlin(seed=3), octaves=6) * 0.5 + worley(1)
+//
+// The following functions are available:
+//
+//	perlin(seed)                      NewPerlin(seed)
+//	simplex(seed)                     NewSimplex(seed)
+//	perlinCatmullRom(seed, cache=2)   NewPerlinCatmullRom(cache, seed)
+//	worley(seed)                      NewWorley(seed)
+//	fbm(noise, octaves=8, persistence=0.5)
+//	octave(persistence, noise...)     NewOctaveNoise with each noise added
+//
+// The fbm function adds the same noise as every octave, which is identical to
+// adding separately constructed generators that share a seed. Seeds default to
+// zero when omitted. The cache of perlinCatmullRom is the number of points
+// sampled along each spline, and must be positive.
+func ParseExpression(src string) (Noiser, error) {
+	p := &exprParser{lex: newExprLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	v, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != exprEOF {
+		return nil, p.tok.errorf("unexpected %s", p.tok)
+	}
+	return v.Noiser(), nil
+}
+
+// exprTokenKind identifies the lexical class of an exprToken.
+type exprTokenKind int
+
+const (
+	exprEOF exprTokenKind = iota
+	exprNumber
+	exprIdent
+	exprPunct
+)
+
+// exprToken is a single lexical token along with its position in the source.
+type exprToken struct {
+	kind         exprTokenKind
+	text         string
+	line, column int
+}
+
+// String describes the token for use in error messages.
+func (t exprToken) String() string {
+	switch t.kind {
+	case exprEOF:
+		return "end of expression"
+	case exprNumber:
+		return "number " + t.text
+	case exprIdent:
+		return "identifier " + strconv.Quote(t.text)
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+// errorf creates an ExpressionError located at this token.
+func (t exprToken) errorf(format string, args ...interface{}) *ExpressionError {
+	return &ExpressionError{
+		Line:   t.line,
+		Column: t.column,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// exprLexer splits an expression into tokens while tracking line and column.
+type exprLexer struct {
+	src          []rune
+	pos          int
+	line, column int
+}
+
+// newExprLexer creates a lexer positioned at the start of src.
+func newExprLexer(src string) *exprLexer {
+	return &exprLexer{
+		src:    []rune(src),
+		line:   1,
+		column: 1,
+	}
+}
+
+// peek returns the rune at the given offset from the current position, or
+// zero past the end of the source.
+func (l *exprLexer) peek(offset int) rune {
+	if l.pos+offset >= len(l.src) {
+		return 0
+	}
+	return l.src[l.pos+offset]
+}
+
+// step consumes one rune, updating the line and column.
+func (l *exprLexer) step() rune {
+	r := l.src[l.pos]
+	l.pos++
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+// peekPunct reports whether the next non-space rune is r, without consuming
+// anything.
+func (l *exprLexer) peekPunct(r rune) bool {
+	for i := 0; l.pos+i < len(l.src); i++ {
+		if c := l.peek(i); !unicode.IsSpace(c) {
+			return c == r
+		}
+	}
+	return false
+}
+
+// next returns the next token in the source.
+func (l *exprLexer) next() (exprToken, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(l.peek(0)) {
+		l.step()
+	}
+	tok := exprToken{line: l.line, column: l.column}
+	if l.pos >= len(l.src) {
+		tok.kind = exprEOF
+		return tok, nil
+	}
+	start := l.pos
+	r := l.peek(0)
+	switch {
+	case unicode.IsLetter(r) || r == '_':
+		for unicode.IsLetter(l.peek(0)) || unicode.IsDigit(l.peek(0)) || l.peek(0) == '_' {
+			l.step()
+		}
+		tok.kind = exprIdent
+	case unicode.IsDigit(r) || (r == '.' && unicode.IsDigit(l.peek(1))):
+		for unicode.IsDigit(l.peek(0)) || l.peek(0) == '.' {
+			l.step()
+		}
+		if e := l.peek(0); e == 'e' || e == 'E' {
+			sign := l.peek(1)
+			if unicode.IsDigit(sign) {
+				l.step()
+			} else if (sign == '+' || sign == '-') && unicode.IsDigit(l.peek(2)) {
+				l.step()
+				l.step()
+			}
+			for unicode.IsDigit(l.peek(0)) {
+				l.step()
+			}
+		}
+		tok.kind = exprNumber
+	case r == '+' || r == '-' || r == '*' || r == '/' || r == '(' || r == ')' || r == ',' || r == '=':
+		l.step()
+		tok.kind = exprPunct
+	default:
+		return tok, tok.errorf("unexpected character %q", r)
+	}
+	tok.text = string(l.src[start:l.pos])
+	return tok, nil
+}
+
+// exprValue is the result of parsing a subexpression. Constant values are
+// kept separate so they can be used as function arguments and folded together.
+type exprValue struct {
+	noiser   Noiser
+	constant float64
+}
+
+// IsConstant reports whether the value does not depend on the sample point.
+func (v exprValue) IsConstant() bool {
+	return v.noiser == nil
+}
+
+// Noiser returns the value as a Noiser, wrapping constants as needed.
+func (v exprValue) Noiser() Noiser {
+	if v.IsConstant() {
+		return constantNoise(v.constant)
+	}
+	return v.noiser
+}
+
+// exprArg is a single argument in a function call.
+type exprArg struct {
+	name  string
+	tok   exprToken
+	value exprValue
+}
+
+// exprParser is a recursive descent parser for noise expressions.
+type exprParser struct {
+	lex *exprLexer
+	tok exprToken
+}
+
+// advance moves to the next token.
+func (p *exprParser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// isPunct reports whether the current token is the given punctuation.
+func (p *exprParser) isPunct(text string) bool {
+	return p.tok.kind == exprPunct && p.tok.text == text
+}
+
+// expect consumes the given punctuation or returns an error.
+func (p *exprParser) expect(text string) error {
+	if !p.isPunct(text) {
+		return p.tok.errorf("expected %q, found %s", text, p.tok)
+	}
+	return p.advance()
+}
+
+// parseSum parses addition and subtraction.
+func (p *exprParser) parseSum() (exprValue, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return left, err
+	}
+	for p.isPunct("+") || p.isPunct("-") {
+		op := p.tok.text[0]
+		if err := p.advance(); err != nil {
+			return left, err
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return left, err
+		}
+		left = combineExprValues(op, left, right)
+	}
+	return left, nil
+}
+
+// parseProduct parses multiplication and division.
+func (p *exprParser) parseProduct() (exprValue, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return left, err
+	}
+	for p.isPunct("*") || p.isPunct("/") {
+		op := p.tok.text[0]
+		if err := p.advance(); err != nil {
+			return left, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return left, err
+		}
+		left = combineExprValues(op, left, right)
+	}
+	return left, nil
+}
+
+// parseUnary parses unary negation and plus.
+func (p *exprParser) parseUnary() (exprValue, error) {
+	if p.isPunct("-") || p.isPunct("+") {
+		op := p.tok.text[0]
+		if err := p.advance(); err != nil {
+			return exprValue{}, err
+		}
+		v, err := p.parseUnary()
+		if err != nil || op == '+' {
+			return v, err
+		}
+		return combineExprValues('-', exprValue{}, v), nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses numbers, calls and parenthesized subexpressions.
+func (p *exprParser) parsePrimary() (exprValue, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == exprNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return exprValue{}, tok.errorf("invalid number %q", tok.text)
+		}
+		return exprValue{constant: f}, p.advance()
+	case tok.kind == exprIdent:
+		if err := p.advance(); err != nil {
+			return exprValue{}, err
+		}
+		args, err := p.parseArgs()
+		if err != nil {
+			return exprValue{}, err
+		}
+		return callExprFunc(tok, args)
+	case p.isPunct("("):
+		if err := p.advance(); err != nil {
+			return exprValue{}, err
+		}
+		v, err := p.parseSum()
+		if err != nil {
+			return v, err
+		}
+		return v, p.expect(")")
+	}
+	return exprValue{}, tok.errorf("unexpected %s", tok)
+}
+
+// parseArgs parses a parenthesized, comma separated argument list.
+func (p *exprParser) parseArgs() ([]exprArg, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []exprArg
+	for !p.isPunct(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg := exprArg{tok: p.tok}
+		if p.tok.kind == exprIdent && p.lex.peekPunct('=') {
+			arg.name = p.tok.text
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+		} else if len(args) > 0 && args[len(args)-1].name != "" {
+			return nil, arg.tok.errorf("positional argument after named argument")
+		}
+		v, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		arg.value = v
+		args = append(args, arg)
+	}
+	return args, p.advance()
+}
+
+// combineExprValues applies a binary operator, folding constants.
+func combineExprValues(op byte, left, right exprValue) exprValue {
+	if left.IsConstant() && right.IsConstant() {
+		return exprValue{constant: applyExprOp(op, left.constant, right.constant)}
+	}
+	return exprValue{noiser: &binaryNoise{
+		op:    op,
+		left:  left.Noiser(),
+		right: right.Noiser(),
+	}}
+}
+
+// applyExprOp evaluates a binary operator on two numbers.
+func applyExprOp(op byte, a, b float64) float64 {
+	switch op {
+	case '+':
+		return a + b
+	case '-':
+		return a - b
+	case '*':
+		return a * b
+	default:
+		return a / b
+	}
+}
+
+// exprCall binds the arguments of a single function call to parameters.
+type exprCall struct {
+	fn     exprToken
+	params []string
+	values []*exprArg
+	rest   []exprArg
+}
+
+// bindExprCall matches positional and named arguments to the parameter
+// names. If variadic is true, extra positional arguments are collected rather
+// than rejected.
+func bindExprCall(fn exprToken, args []exprArg, variadic bool, params ...string) (*exprCall, error) {
+	c := &exprCall{
+		fn:     fn,
+		params: params,
+		values: make([]*exprArg, len(params)),
+	}
+	for i := range args {
+		arg := &args[i]
+		idx := -1
+		if arg.name == "" {
+			if i < len(params) {
+				idx = i
+			} else if variadic {
+				c.rest = append(c.rest, *arg)
+				continue
+			} else {
+				return nil, arg.tok.errorf("too many arguments to %s", fn.text)
+			}
+		} else {
+			for j, name := range params {
+				if name == arg.name {
+					idx = j
+				}
+			}
+			if idx < 0 {
+				return nil, arg.tok.errorf("%s has no parameter %q", fn.text, arg.name)
+			}
+		}
+		if c.values[idx] != nil {
+			return nil, arg.tok.errorf("parameter %q given more than once", params[idx])
+		}
+		c.values[idx] = arg
+	}
+	return c, nil
+}
+
+// arg returns the argument bound to the named parameter, or nil.
+func (c *exprCall) arg(name string) *exprArg {
+	for i, p := range c.params {
+		if p == name {
+			return c.values[i]
+		}
+	}
+	return nil
+}
+
+// Number returns a constant argument, or def if it was not given.
+func (c *exprCall) Number(name string, def float64) (float64, error) {
+	a := c.arg(name)
+	if a == nil {
+		return def, nil
+	}
+	if !a.value.IsConstant() {
+		return 0, a.tok.errorf("parameter %q of %s must be a number", name, c.fn.text)
+	}
+	return a.value.constant, nil
+}
+
+// Int returns a constant integer argument, or def if it was not given.
+func (c *exprCall) Int(name string, def int64) (int64, error) {
+	f, err := c.Number(name, float64(def))
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+		return 0, c.arg(name).tok.errorf("parameter %q of %s must be an integer", name, c.fn.text)
+	}
+	return int64(f), nil
+}
+
+// Noiser returns a required argument as a Noiser.
+func (c *exprCall) Noiser(name string) (Noiser, error) {
+	a := c.arg(name)
+	if a == nil {
+		return nil, c.fn.errorf("%s requires parameter %q", c.fn.text, name)
+	}
+	return a.value.Noiser(), nil
+}
+
+// callExprFunc evaluates a call to one of the built in functions.
+func callExprFunc(fn exprToken, args []exprArg) (exprValue, error) {
+	switch fn.text {
+	case "perlin", "simplex", "worley":
+		c, err := bindExprCall(fn, args, false, "seed")
+		if err != nil {
+			return exprValue{}, err
+		}
+		seed, err := c.Int("seed", 0)
+		if err != nil {
+			return exprValue{}, err
+		}
+		switch fn.text {
+		case "perlin":
+			return exprValue{noiser: NewPerlin(seed)}, nil
+		case "worley":
+			return exprValue{noiser: NewWorley(seed)}, nil
+		}
+		return exprValue{noiser: NewSimplex(seed)}, nil
+	case "perlinCatmullRom":
+		c, err := bindExprCall(fn, args, false, "seed", "cache")
+		if err != nil {
+			return exprValue{}, err
+		}
+		seed, err := c.Int("seed", 0)
+		if err != nil {
+			return exprValue{}, err
+		}
+		cache, err := c.Int("cache", 2)
+		if err != nil {
+			return exprValue{}, err
+		}
+		if cache < 1 || cache > math.MaxInt32 {
+			return exprValue{}, c.arg("cache").tok.errorf("perlinCatmullRom requires a positive cache size")
+		}
+		return exprValue{noiser: NewPerlinCatmullRom(int(cache), seed)}, nil
+	case "fbm":
+		c, err := bindExprCall(fn, args, false, "noise", "octaves", "persistence")
+		if err != nil {
+			return exprValue{}, err
+		}
+		n, err := c.Noiser("noise")
+		if err != nil {
+			return exprValue{}, err
+		}
+		octaves, err := c.Int("octaves", 8)
+		if err != nil {
+			return exprValue{}, err
+		}
+		if octaves < 1 {
+			return exprValue{}, c.arg("octaves").tok.errorf("fbm requires at least one octave")
+		}
+		persistence, err := c.Number("persistence", 0.5)
+		if err != nil {
+			return exprValue{}, err
+		}
+		o := NewOctaveNoise(persistence)
+		for i := int64(0); i < octaves; i++ {
+			o.AddOctave(n)
+		}
+		return exprValue{noiser: o}, nil
+	case "octave":
+		c, err := bindExprCall(fn, args, true, "persistence")
+		if err != nil {
+			return exprValue{}, err
+		}
+		persistence, err := c.Number("persistence", 0.5)
+		if err != nil {
+			return exprValue{}, err
+		}
+		o := NewOctaveNoise(persistence)
+		for _, a := range c.rest {
+			o.AddOctave(a.value.Noiser())
+		}
+		return exprValue{noiser: o}, nil
+	}
+	return exprValue{}, fn.errorf("unknown function %q", fn.text)
+}
+
+var _ Noiser = constantNoise(0)
+
+// constantNoise is a Noiser that returns the same value everywhere.
+type constantNoise float64
+
+// Noise returns the constant value.
+func (c constantNoise) Noise(x, y float64) float64 {
+	return float64(c)
+}
+
+var _ BatchNoiser = &binaryNoise{}
+
+// binaryNoise combines two Noisers with an arithmetic operator.
+type binaryNoise struct {
+	op          byte
+	left, right Noiser
+}
+
+// Noise applies the operator to both Noisers' values at the point.
+func (b *binaryNoise) Noise(x, y float64) float64 {
+	return applyExprOp(b.op, b.left.Noise(x, y), b.right.Noise(x, y))
+}
+
+// NoiseBatch applies the operator to both Noisers' values at many points.
+func (b *binaryNoise) NoiseBatch(xs, ys, out []float64) {
+	right := make([]float64, len(out))
+	NoiseBatch(b.left, xs, ys, out)
+	NoiseBatch(b.right, xs, ys, right)
+	for i := range out {
+		out[i] = applyExprOp(b.op, out[i], right[i])
+	}
+}
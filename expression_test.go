@@ -0,0 +1,107 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"testing"
+)
+
+func assertSameNoise(t *testing.T, expr string, want Noiser) {
+	got, err := ParseExpression(expr)
+	if err != nil {
+		t.Fatalf("%s: %s", expr, err)
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			nx := startCorner + float64(x)*sampleStep*7
+			ny := startCorner + float64(y)*sampleStep*7
+			if g, w := got.Noise(nx, ny), want.Noise(nx, ny); g != w {
+				t.Fatalf("%s: Noise(%v, %v) = %v, want %v", expr, nx, ny, g, w)
+			}
+		}
+	}
+}
+
+func TestExpressionMatchesConstructors(t *testing.T) {
+	assertSameNoise(t, "perlin(42)", NewPerlin(seed))
+	assertSameNoise(t, "simplex(seed = 42)", NewSimplex(seed))
+	assertSameNoise(t, "perlinCatmullRom(42, cache=2)", NewPerlinCatmullRom(splineCacheSize, seed))
+	assertSameNoise(t, "perlinCatmullRom(42, cache=16)", NewPerlinCatmullRom(16, seed))
+	assertSameNoise(t, "worley(seed=42)", NewWorley(seed))
+
+	pink := NewOctaveNoise(0.5)
+	for i := 0; i < 6; i++ {
+		pink.AddOctave(NewPerlin(3))
+	}
+	assertSameNoise(t, "fbm(perlin(seed=3), octaves=6)", pink)
+
+	mixed := NewOctaveNoise(0.25)
+	mixed.AddOctave(NewPerlin(1))
+	mixed.AddOctave(NewSimplex(2))
+	assertSameNoise(t, "octave(0.25, perlin(1), simplex(2))", mixed)
+
+	perlin := NewPerlin(3)
+	simplex := NewSimplex(1)
+	assertSameNoise(t, "fbm(perlin(seed=3), octaves=6) * 0.5 + simplex(1)", &binaryNoise{
+		op:    '+',
+		left:  &binaryNoise{op: '*', left: pink, right: constantNoise(0.5)},
+		right: simplex,
+	})
+	assertSameNoise(t, "fbm(perlin(seed=3), octaves=6) * 0.5 + worley(1)", &binaryNoise{
+		op:    '+',
+		left:  &binaryNoise{op: '*', left: pink, right: constantNoise(0.5)},
+		right: NewWorley(1),
+	})
+	assertSameNoise(t, "-(perlin(3) - 2 * 0.25) / 4", &binaryNoise{
+		op:    '/',
+		left:  &binaryNoise{op: '-', left: constantNoise(0), right: &binaryNoise{op: '-', left: perlin, right: constantNoise(0.5)}},
+		right: constantNoise(4),
+	})
+	assertSameNoise(t, "1.5e1", constantNoise(15))
+}
+
+func TestExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr         string
+		line, column int
+	}{
+		{"perlin(1) +", 1, 12},
+		{"perlin(1) $ 2", 1, 11},
+		{"perlin(1)\n  * voronoi(1)", 2, 5},
+		{"perlinCatmullRom(1, cache=0)", 1, 21},
+		{"fbm(octaves=2)", 1, 1},
+		{"fbm(perlin(1),\n octaves=simplex(2))", 2, 2},
+		{"perlin(seed=1, 2)", 1, 16},
+		{"perlin(1.5)", 1, 8},
+		{"simplex(1, 2)", 1, 12},
+		{"perlin(bogus=1)", 1, 8},
+		{"(perlin(1)", 1, 11},
+		{"perlin(1) perlin(2)", 1, 11},
+	}
+	for _, test := range tests {
+		_, err := ParseExpression(test.expr)
+		exprErr, ok := err.(*ExpressionError)
+		if !ok {
+			t.Errorf("%q: got error %v, want *ExpressionError", test.expr, err)
+			continue
+		}
+		if exprErr.Line != test.line || exprErr.Column != test.column {
+			t.Errorf("%q: got %s, want line %d, column %d", test.expr, exprErr, test.line, test.column)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+const float32TestRange = 1000
+
+func testFloat32Matches(t *testing.T, n32 Noiser32, n Noiser) {
+	worst := 0.0
+	for y := 0; y < imageDimension; y++ {
+		for x := 0; x < imageDimension; x++ {
+			nx := float32(-float32TestRange + float64(x)*2*float32TestRange/imageDimension + sampleStep)
+			ny := float32(-float32TestRange + float64(y)*2*float32TestRange/imageDimension + sampleStep)
+			diff := math.Abs(float64(n32.Noise32(nx, ny)) - n.Noise(float64(nx), float64(ny)))
+			if diff > worst {
+				worst = diff
+			}
+			if diff > Float32Tolerance {
+				t.Fatalf("Noise32(%v, %v) differs by %v", nx, ny, diff)
+			}
+		}
+	}
+	t.Logf("largest difference %v", worst)
+}
+
+func TestPerlin32(t *testing.T) {
+	testFloat32Matches(t, NewPerlin32(seed), NewPerlin(seed))
+}
+
+func TestSimplex32(t *testing.T) {
+	testFloat32Matches(t, NewSimplex32(seed), NewSimplex(seed))
+}
@@ -0,0 +1,97 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"testing"
+)
+
+// goldenPoints are the coordinates at which the golden values are sampled.
+var goldenPoints = [][2]float64{
+	{0.5, 0.5},
+	{-3.25, 7.75},
+	{100.137, -42.9},
+	{1234.5678, -8765.4321},
+	{-0.001, 0.999},
+}
+
+// goldenOctaves builds pink noise from four octaves made by mk.
+func goldenOctaves(mk func() Noiser) Noiser {
+	o := NewOctaveNoise(0.5)
+	for i := 0; i < 4; i++ {
+		o.AddOctave(mk())
+	}
+	return o
+}
+
+func assertGolden(t *testing.T, name string, n Noiser, want []float64) {
+	for i, p := range goldenPoints {
+		if got := n.Noise(p[0], p[1]); got != want[i] {
+			t.Errorf("%s: Noise(%v, %v) = %v, want %v", name, p[0], p[1], got, want[i])
+		}
+	}
+}
+
+func TestGoldenSeededNoise(t *testing.T) {
+	assertGolden(t, "Perlin", NewPerlin(42), []float64{
+		-0.24999999999999992, -0.1529128972764366, 0.14493896743257126, 3.3879446309883696e-05, 0.0013660290412560543,
+	})
+	assertGolden(t, "Simplex", NewSimplex(42), []float64{
+		-0.005994051436466271, -0.00413668522027273, -0.003524981351926107, 0.0010193135932365731, -0.006822937333785136,
+	})
+	assertGolden(t, "PerlinCatmullRom", NewPerlinCatmullRom(splineCacheSize, 42), []float64{
+		-0.2486154223789187, 0.02066423437264467, 0.04776774399776479, 0.10584857394971024, 0.001972780349262626,
+	})
+	assertGolden(t, "SmoothPerlinCatmullRom", NewSmoothPerlinCatmullRom(42), []float64{
+		-0.26325119080239545, -0.01874700863486012, 0.12240138386966133, 0.08261159008523987, 0.0024158542073356992,
+	})
+	assertGolden(t, "OctaveNoise", goldenOctaves(func() Noiser { return NewPerlin(42) }), []float64{
+		-0.24999999999999992, -0.300789485012964, 0.37647266828704806, 0.08457791140710445, -0.000731193441180474,
+	})
+}
+
+func TestGoldenStableTableNoise(t *testing.T) {
+	table := NewStablePermutationTable(42, DefaultTableSize)
+	assertGolden(t, "Perlin", NewPerlinFromTable(table, nil, nil), []float64{
+		-0.04575317547305474, 0.3111599922119066, -0.03231315333749305, -0.12227210193082091, 0.0013660154014832786,
+	})
+	assertGolden(t, "Simplex", NewSimplexFromTable(table, nil), []float64{
+		-0.0059940514364662705, 0.008175815507908024, 0.0024114548140802806, 0.002799103347084278, -0.007202714934945445,
+	})
+	assertGolden(t, "PerlinCatmullRom", NewPerlinCatmullRomFromTable(table), []float64{
+		-0.1512356785104805, 0.3236183220043866, -0.12007712111247659, -0.1771290368057315, 0.0015468242179433878,
+	})
+	assertGolden(t, "OctaveNoise", goldenOctaves(func() Noiser { return NewSimplexFromTable(table, nil) }), []float64{
+		-0.005845466150877141, 0.00557812838522125, 0.0018790156960819943, 0.00772949647481002, -0.005915663803870195,
+	})
+}
+
+func TestStablePermutationTableIsStable(t *testing.T) {
+	s := newSplitMix64(0)
+	for i, want := range []uint64{16294208416658607535, 7960286522194355700, 487617019471545679} {
+		if got := s.Uint64(); got != want {
+			t.Errorf("splitMix64 value %d = %d, want %d", i, got, want)
+		}
+	}
+	table := NewStablePermutationTable(42, DefaultTableSize)
+	for i, want := range []int{6074, 1309, 2282, 2819, 311, 7112, 1789, 6558} {
+		if table.hash[i] != want {
+			t.Errorf("table entry %d = %d, want %d", i, table.hash[i], want)
+		}
+	}
+}
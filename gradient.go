@@ -0,0 +1,39 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+// gradientStep is the distance between the samples of a finite difference.
+const gradientStep = 1e-4
+
+// NoiseGradient returns the partial derivatives of the noise along x and y at
+// the point. If the Noiser is also a GradientNoiser its NoiseGradient method is
+// used, otherwise the derivatives are estimated with central differences.
+func NoiseGradient(n Noiser, x, y float64) (dx, dy float64) {
+	if g, ok := n.(GradientNoiser); ok {
+		return g.NoiseGradient(x, y)
+	}
+	return finiteDifferenceGradient(n, x, y)
+}
+
+// finiteDifferenceGradient estimates the gradient of the noise with central
+// differences.
+func finiteDifferenceGradient(n Noiser, x, y float64) (dx, dy float64) {
+	dx = (n.Noise(x+gradientStep, y) - n.Noise(x-gradientStep, y)) / (2 * gradientStep)
+	dy = (n.Noise(x, y+gradientStep) - n.Noise(x, y-gradientStep)) / (2 * gradientStep)
+	return
+}
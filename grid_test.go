@@ -0,0 +1,73 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+const gridTolerance = 1e-9
+
+func testGridMatchesNoise(t *testing.T, n Noiser, minX, minY, dx, dy float64) {
+	const stride = 150
+	dst := make([]float64, stride*40)
+	FillGrid(n, dst, stride, minX, minY, dx, dy)
+	for row := 0; row < len(dst)/stride; row++ {
+		for col := 0; col < stride; col++ {
+			x := minX + float64(col)*dx
+			y := minY + float64(row)*dy
+			want := n.Noise(x, y)
+			if got := dst[row*stride+col]; math.Abs(got-want) > gridTolerance {
+				t.Fatalf("FillGrid at (%v, %v) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestPerlinFillGrid(t *testing.T) {
+	testGridMatchesNoise(t, NewPerlin(seed), startCorner, startCorner, sampleStep, sampleStep)
+	testGridMatchesNoise(t, NewPerlin(seed), 3.5, -0.25, -0.61, 1.7)
+	testGridMatchesNoise(t, NewPerlinHashed(seed), startCorner, startCorner, sampleStep, sampleStep)
+	testGridMatchesNoise(t, NewPerlinHashed(seed), 3.5, -0.25, -0.61, 1.7)
+}
+
+func TestSimplexFillGrid(t *testing.T) {
+	testGridMatchesNoise(t, NewSimplex(seed), startCorner, startCorner, sampleStep, sampleStep)
+	testGridMatchesNoise(t, NewSimplex(seed), 3.5, -0.25, -0.61, 1.7)
+}
+
+func TestFallbackFillGrid(t *testing.T) {
+	testGridMatchesNoise(t, pinkOctave(NewPerlin(seed)), startCorner, startCorner, sampleStep, sampleStep)
+}
+
+func benchmarkFillGrid(b *testing.B, n Noiser) {
+	dst := make([]float64, imageSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FillGrid(n, dst, imageDimension, startCorner, startCorner, sampleStep, sampleStep)
+	}
+}
+
+func BenchmarkPerlinFillGrid(b *testing.B) {
+	benchmarkFillGrid(b, NewPerlin(seed))
+}
+
+func BenchmarkSimplexFillGrid(b *testing.B) {
+	benchmarkFillGrid(b, NewSimplex(seed))
+}
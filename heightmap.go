@@ -0,0 +1,195 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// The heightmap writers take the same sampling parameters as
+// WriteGreyImagePng. Except for PFM, which is stored bottom to top, their rows
+// are written from the top of the image down, so that the first row written
+// is the one sampled at the largest Y, matching the PNG writers.
+
+// WriteFloat32Raw writes the noise as a grid of little-endian float32 values
+// with no header, one row after another.
+func WriteFloat32Raw(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64) error {
+	return writeRaw(w, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, 4, func(b []byte, v float64) {
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+	})
+}
+
+// WriteFloat64Raw writes the noise as a grid of little-endian float64 values
+// with no header, one row after another.
+func WriteFloat64Raw(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64) error {
+	return writeRaw(w, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, 8, func(b []byte, v float64) {
+		binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+	})
+}
+
+// WriteRaw16 writes the noise as a 16-bit RAW heightmap of little-endian
+// unsigned values with no header, as imported by Unity and Unreal. The
+// Normalizer maps the noise onto the full range of heights, and a nil
+// Normalizer stretches the samples like LatestVersion.
+func WriteRaw16(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, normalizer Normalizer) error {
+	grays, normalize, err := sampleNormalized(context.Background(), noiser, float64(minX), float64(minY), numberSamplesX, numberSamplesY, noiseSampleDelta, 0, normalizer)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	var b [2]byte
+	for y := len(grays) - 1; y >= 0; y-- {
+		for _, v := range grays[y] {
+			binary.LittleEndian.PutUint16(b[:], uint16(linearInterpolation(0, 65535, normalize(v))))
+			bw.Write(b[:])
+		}
+	}
+	return bw.Flush()
+}
+
+// WritePFM writes the noise as a greyscale portable float map, which stores
+// the samples unnormalized as little-endian float32 values. As the format
+// requires, the rows are written from the bottom of the image up.
+func WritePFM(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64) error {
+	grays, err := sampleImageRows(noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	// A negative scale marks the data as little-endian.
+	fmt.Fprintf(bw, "Pf\n%d %d\n-1.0\n", numberSamplesX, numberSamplesY)
+	var b [4]byte
+	for _, row := range grays {
+		for _, v := range row {
+			binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(v)))
+			bw.Write(b[:])
+		}
+	}
+	return bw.Flush()
+}
+
+// WritePGM writes the noise as an ASCII portable graymap with 16-bit shades.
+// The Normalizer maps the noise onto the shades, and a nil Normalizer
+// stretches the samples like LatestVersion. Each row starts on a new line,
+// and long rows are wrapped so that no line exceeds 70 characters.
+func WritePGM(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, normalizer Normalizer) error {
+	grays, normalize, err := sampleNormalized(context.Background(), noiser, float64(minX), float64(minY), numberSamplesX, numberSamplesY, noiseSampleDelta, 0, normalizer)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P2\n%d %d\n65535\n", numberSamplesX, numberSamplesY)
+	a := &asciiMapWriter{bw: bw}
+	for y := len(grays) - 1; y >= 0; y-- {
+		for _, v := range grays[y] {
+			a.value(int(uint16(linearInterpolation(0, 65535, normalize(v)))))
+		}
+		a.endRow()
+	}
+	return bw.Flush()
+}
+
+// WritePPM writes the noise as an ASCII portable pixmap, coloring each
+// normalized sample with the ramp. A nil Normalizer stretches the samples like
+// LatestVersion, and a nil ramp is GrayscaleRamp. The alpha of the ramp's
+// colors is ignored. Lines are wrapped like those of WritePGM.
+func WritePPM(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, normalizer Normalizer, ramp *ColorRamp) error {
+	grays, normalize, err := sampleNormalized(context.Background(), noiser, float64(minX), float64(minY), numberSamplesX, numberSamplesY, noiseSampleDelta, 0, normalizer)
+	if err != nil {
+		return err
+	}
+	if ramp == nil {
+		ramp = GrayscaleRamp()
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P3\n%d %d\n255\n", numberSamplesX, numberSamplesY)
+	a := &asciiMapWriter{bw: bw}
+	for y := len(grays) - 1; y >= 0; y-- {
+		for _, v := range grays[y] {
+			c := ramp.At(normalize(v))
+			a.value(int(c.R))
+			a.value(int(c.G))
+			a.value(int(c.B))
+		}
+		a.endRow()
+	}
+	return bw.Flush()
+}
+
+// maxASCIILine is the longest line that the ASCII portable map formats allow.
+const maxASCIILine = 70
+
+// asciiMapWriter writes the values of an ASCII portable map separated by
+// spaces, wrapping lines before they exceed maxASCIILine characters.
+type asciiMapWriter struct {
+	bw   *bufio.Writer
+	line int
+}
+
+// value writes the next value.
+func (a *asciiMapWriter) value(v int) {
+	s := strconv.Itoa(v)
+	if a.line > 0 && a.line+1+len(s) > maxASCIILine {
+		a.bw.WriteByte('\n')
+		a.line = 0
+	}
+	if a.line > 0 {
+		a.bw.WriteByte(' ')
+		a.line++
+	}
+	a.bw.WriteString(s)
+	a.line += len(s)
+}
+
+// endRow ends the current row, so that the next value starts a new line.
+func (a *asciiMapWriter) endRow() {
+	a.bw.WriteByte('\n')
+	a.line = 0
+}
+
+// writeRaw writes the samples from the top row down, encoding each into size
+// bytes with put.
+func writeRaw(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, size int, put func(b []byte, v float64)) error {
+	grays, err := sampleImageRows(noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	b := make([]byte, size)
+	for y := len(grays) - 1; y >= 0; y-- {
+		for _, v := range grays[y] {
+			put(b, v)
+			bw.Write(b)
+		}
+	}
+	return bw.Flush()
+}
+
+// sampleImageRows samples the rows of an image without normalizing them.
+func sampleImageRows(noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64) ([][]float64, error) {
+	if numberSamplesX <= 0 || numberSamplesY <= 0 {
+		return nil, errInvalidDimensions
+	}
+	return sampleRows(context.Background(), noiser, float64(minX), float64(minY), numberSamplesX, numberSamplesY, noiseSampleDelta, 0)
+}
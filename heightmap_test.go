@@ -0,0 +1,155 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteFloatRaw(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteFloat32Raw(&buffer, rowNoise{}, 0, 1, 2, 3, 1); err != nil {
+		t.Fatal(err)
+	}
+	got32 := make([]float32, 6)
+	if err := binary.Read(&buffer, binary.LittleEndian, got32); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float32{3, 3, 2, 2, 1, 1} {
+		if got32[i] != want {
+			t.Errorf("float32 value %d = %v, want %v", i, got32[i], want)
+		}
+	}
+
+	n := NewPerlin(seed)
+	buffer.Reset()
+	if err := WriteFloat64Raw(&buffer, n, 0, 0, 4, 2, sampleStep); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.Len() != 4*2*8 {
+		t.Fatalf("wrote %d bytes, want %d", buffer.Len(), 4*2*8)
+	}
+	// The first value is the top left corner, sampled at the largest Y.
+	got := math.Float64frombits(binary.LittleEndian.Uint64(buffer.Bytes()))
+	if want := n.Noise(0, sampleStep); got != want {
+		t.Errorf("first float64 value = %v, want %v", got, want)
+	}
+}
+
+func TestWriteRaw16(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteRaw16(&buffer, rowNoise{}, 0, 1, 2, 3, 1, &FixedRange{Min: 1, Max: 3}); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]uint16, 6)
+	if err := binary.Read(&buffer, binary.LittleEndian, got); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []uint16{65535, 65535, 32767, 32767, 0, 0} {
+		if got[i] != want {
+			t.Errorf("height %d = %d, want %d", i, got[i], want)
+		}
+	}
+}
+
+func TestWritePFM(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WritePFM(&buffer, rowNoise{}, 0, 1, 2, 3, 1); err != nil {
+		t.Fatal(err)
+	}
+	header := "Pf\n2 3\n-1.0\n"
+	if got := buffer.String()[:len(header)]; got != header {
+		t.Fatalf("header = %q, want %q", got, header)
+	}
+	buffer.Next(len(header))
+	got := make([]float32, 6)
+	if err := binary.Read(&buffer, binary.LittleEndian, got); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float32{1, 1, 2, 2, 3, 3} {
+		if got[i] != want {
+			t.Errorf("value %d = %v, want %v", i, got[i], want)
+		}
+	}
+}
+
+func TestWriteASCIIMaps(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WritePGM(&buffer, rowNoise{}, 0, 1, 2, 3, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buffer.String(), "P2\n2 3\n65535\n65535 65535\n32767 32767\n0 0\n"; got != want {
+		t.Errorf("PGM = %q, want %q", got, want)
+	}
+
+	// A nil ramp is grayscale.
+	for _, ramp := range []*ColorRamp{GrayscaleRamp(), nil} {
+		buffer.Reset()
+		if err := WritePPM(&buffer, rowNoise{}, 0, 1, 2, 3, 1, nil, ramp); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := buffer.String(), "P3\n2 3\n255\n255 255 255 255 255 255\n128 128 128 128 128 128\n0 0 0 0 0 0\n"; got != want {
+			t.Errorf("PPM = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestWriteASCIIMapsWrapLines(t *testing.T) {
+	var pgm, ppm bytes.Buffer
+	if err := WritePGM(&pgm, columnNoise{}, 0, 0, 40, 3, 0.1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePPM(&ppm, columnNoise{}, 0, 0, 40, 3, 0.1, nil, TerrainRamp()); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		text   string
+		values int
+	}{
+		{"PGM", pgm.String(), 4 + 40*3},
+		{"PPM", ppm.String(), 4 + 40*3*3},
+	}
+	for _, test := range tests {
+		for _, line := range strings.Split(test.text, "\n") {
+			if len(line) > 70 {
+				t.Errorf("%s line has %d characters: %q", test.name, len(line), line)
+			}
+		}
+		if got := len(strings.Fields(test.text)); got != test.values {
+			t.Errorf("%s has %d fields, want %d", test.name, got, test.values)
+		}
+	}
+}
+
+func TestHeightmapInvalidDimensions(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteFloat32Raw(&buffer, rowNoise{}, 0, 0, 0, 3, 1); err == nil {
+		t.Error("WriteFloat32Raw accepted an empty grid")
+	}
+	if err := WritePFM(&buffer, rowNoise{}, 0, 0, 3, -1, 1); err == nil {
+		t.Error("WritePFM accepted an empty grid")
+	}
+	if err := WritePGM(&buffer, rowNoise{}, 0, 0, 0, 0, 1, nil); err == nil {
+		t.Error("WritePGM accepted an empty grid")
+	}
+}
@@ -22,3 +22,36 @@ package noise
 type Noiser interface {
 	Noise(x, y float64) float64
 }
+
+// BatchNoiser is a Noiser that can efficiently generate noise for many points
+// at once. NoiseBatch writes the noise for the point (xs[i], ys[i]) into
+// out[i] for every index of out, and must produce the same values as calling
+// Noise for each point. The xs and ys slices must be at least as long as out,
+// and out must not share memory with either of them.
+type BatchNoiser interface {
+	Noiser
+	NoiseBatch(xs, ys, out []float64)
+}
+
+// GridNoiser is a Noiser that can efficiently sample a regular grid. FillGrid
+// fills len(dst)/stride rows of stride samples each, where dst[row*stride+col]
+// is the noise at (minX+col*dx, minY+row*dy). The values may differ from Noise
+// by floating point rounding.
+type GridNoiser interface {
+	Noiser
+	FillGrid(dst []float64, stride int, minX, minY, dx, dy float64)
+}
+
+// GradientNoiser is a Noiser that can compute the gradient of its noise, the
+// partial derivatives of the noise along x and y, more exactly than finite
+// differences.
+type GradientNoiser interface {
+	Noiser
+	NoiseGradient(x, y float64) (dx, dy float64)
+}
+
+// Noiser32 generates single precision noise for a point. The noise never
+// changes for the same point and Noiser32.
+type Noiser32 interface {
+	Noise32(x, y float32) float32
+}
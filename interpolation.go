@@ -0,0 +1,141 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import "math"
+
+// FadeFunc maps a fractional position 0 <= t <= 1 within a lattice cell to
+// the weight given to the upper side of the cell. It must return zero for zero
+// and one for one.
+type FadeFunc func(t float64) float64
+
+// Fade is a fading function for Perlin noise together with its first
+// derivative, which Perlin uses to compute its gradient exactly.
+type Fade struct {
+	fade       FadeFunc
+	derivative FadeFunc
+}
+
+// NewFade pairs a fading function with its first derivative. Perlin noise
+// with a nil derivative estimates its gradient by finite differences, and a
+// nil fading function uses QuinticFade.
+func NewFade(fade, derivative FadeFunc) *Fade {
+	if fade == nil {
+		return QuinticFade
+	}
+	return &Fade{fade: fade, derivative: derivative}
+}
+
+// At returns the weight of the upper side of a lattice cell at the fractional
+// position t.
+func (f *Fade) At(t float64) float64 {
+	return f.fade(t)
+}
+
+var (
+	// LinearFade does not fade at all. It is the fastest, but the noise has
+	// visible creases along the lattice cell boundaries.
+	LinearFade = &Fade{fade: linearFade, derivative: linearFadeDerivative}
+	// CubicFade is the smoothstep curve 3t^2-2t^3 used by the original
+	// Perlin noise. Its first derivative is zero at the interpolation
+	// boundaries.
+	CubicFade = &Fade{fade: cubicFade, derivative: cubicFadeDerivative}
+	// QuinticFade is the curve 6t^5-15t^4+10t^3 of improved Perlin noise.
+	// Its first and second derivatives are zero at the interpolation
+	// boundaries. It is the default for Perlin.
+	QuinticFade = &Fade{fade: fader, derivative: quinticFadeDerivative}
+	// CosineFade fades along half of a cosine wave. Its first derivative is
+	// zero at the interpolation boundaries.
+	CosineFade = &Fade{fade: cosineFade, derivative: cosineFadeDerivative}
+)
+
+// linearFade is the fading function of LinearFade.
+func linearFade(t float64) float64 {
+	return t
+}
+
+// linearFadeDerivative is the first derivative of LinearFade.
+func linearFadeDerivative(t float64) float64 {
+	return 1
+}
+
+// cubicFade is the fading function of CubicFade.
+func cubicFade(t float64) float64 {
+	return t * t * (3 - float64(2*t))
+}
+
+// cubicFadeDerivative is the first derivative of CubicFade.
+func cubicFadeDerivative(t float64) float64 {
+	return 6 * t * (1 - t)
+}
+
+// quinticFadeDerivative is the first derivative of QuinticFade.
+func quinticFadeDerivative(t float64) float64 {
+	u := t * (t - 1)
+	return 30 * u * u
+}
+
+// cosineFade is the fading function of CosineFade.
+func cosineFade(t float64) float64 {
+	return (1 - math.Cos(t*math.Pi)) / 2
+}
+
+// cosineFadeDerivative is the first derivative of CosineFade.
+func cosineFadeDerivative(t float64) float64 {
+	return math.Sin(t*math.Pi) * (math.Pi / 2)
+}
+
+// CubicInterpolator interpolates between p1 and p2 using a fractional t value
+// in the range 0 <= t <= 1, where p0, p1, p2 and p3 are evenly spaced.
+type CubicInterpolator func(p0, p1, p2, p3, t float64) float64
+
+// CatmullRomInterpolator passes through p1 and p2 with a continuous first
+// derivative. It is the interpolation used by NewSmoothPerlinCatmullRom.
+func CatmullRomInterpolator(p0, p1, p2, p3, t float64) float64 {
+	return cubicCatmullRom(p0, p1, p2, p3, t)
+}
+
+// BSplineInterpolator evaluates a uniform cubic B-spline. It has continuous
+// first and second derivatives, but does not pass through p1 and p2, which
+// gives a softer result with a smaller range of values.
+func BSplineInterpolator(p0, p1, p2, p3, t float64) float64 {
+	t2 := t * t
+	t3 := float64(t2 * t)
+	return (float64(p0*(1-float64(3*t)+float64(3*t2)-t3)) +
+		float64(p1*(4-float64(6*t2)+float64(3*t3))) +
+		float64(p2*(1+float64(3*t)+float64(3*t2)-float64(3*t3))) +
+		float64(p3*t3)) / 6
+}
+
+// HermiteInterpolator returns a cubic Hermite interpolation that passes
+// through p1 and p2 with tangents given by the central differences of the
+// neighboring points scaled by one minus the tension. A tension of zero is the
+// same as CatmullRomInterpolator, and a tension of one has flat tangents.
+func HermiteInterpolator(tension float64) CubicInterpolator {
+	scale := (1 - tension) / 2
+	return func(p0, p1, p2, p3, t float64) float64 {
+		m1 := (p2 - p0) * scale
+		m2 := (p3 - p1) * scale
+		t2 := float64(t * t)
+		t3 := float64(t2 * t)
+		return float64((float64(2*t3)-float64(3*t2)+1)*p1) +
+			float64((t3-float64(2*t2)+t)*m1) +
+			float64((float64(-2*t3)+float64(3*t2))*p2) +
+			float64((t3-t2)*m2)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFadeFuncEndpoints(t *testing.T) {
+	fades := map[string]*Fade{
+		"linear":  LinearFade,
+		"cubic":   CubicFade,
+		"quintic": QuinticFade,
+		"cosine":  CosineFade,
+	}
+	for name, fade := range fades {
+		if fade.At(0) != 0 || math.Abs(fade.At(1)-1) > 1e-15 || math.Abs(fade.At(0.5)-0.5) > 1e-15 {
+			t.Errorf("%s fade gives %v, %v, %v at 0, 0.5, 1", name, fade.At(0), fade.At(0.5), fade.At(1))
+		}
+	}
+}
+
+func TestFadeDerivatives(t *testing.T) {
+	const h = 1e-6
+	fades := map[string]*Fade{
+		"linear":  LinearFade,
+		"cubic":   CubicFade,
+		"quintic": QuinticFade,
+		"cosine":  CosineFade,
+	}
+	for name, fade := range fades {
+		derivative := fade.derivative
+		if derivative == nil {
+			t.Errorf("%s fade has no derivative", name)
+			continue
+		}
+		for _, x := range []float64{0.1, 0.25, 0.5, 0.7, 0.95} {
+			want := (fade.At(x+h) - fade.At(x-h)) / (2 * h)
+			if got := derivative(x); math.Abs(got-want) > 1e-6 {
+				t.Errorf("%s derivative at %v = %v, want %v", name, x, got, want)
+			}
+		}
+		if NewPerlinWithFade(seed, fade).fadeDerivative == nil {
+			t.Errorf("Perlin with the %s fade estimates its gradient", name)
+		}
+	}
+	square := func(t float64) float64 { return t * t }
+	if NewPerlinWithFade(seed, NewFade(square, nil)).fadeDerivative != nil {
+		t.Error("Perlin with a fade without a derivative has one")
+	}
+	// A wrapper of a built-in fade keeps the derivative it is given.
+	wrapped := NewFade(func(t float64) float64 { return CubicFade.At(t) }, cubicFadeDerivative)
+	wrappedPerlin := NewPerlinWithFade(seed, wrapped)
+	dx, dy := wrappedPerlin.NoiseGradient(startCorner+0.3, startCorner+0.6)
+	wantDX, wantDY := NewPerlinWithFade(seed, CubicFade).NoiseGradient(startCorner+0.3, startCorner+0.6)
+	if dx != wantDX || dy != wantDY {
+		t.Errorf("wrapped cubic fade gradient = %v, %v, want %v, %v", dx, dy, wantDX, wantDY)
+	}
+	if NewFade(nil, nil) != QuinticFade {
+		t.Error("NewFade without a fading function is not QuinticFade")
+	}
+}
+
+func TestPerlinDefaultFade(t *testing.T) {
+	assertSameNoise(t, "perlin(42)", NewPerlinWithFade(seed, QuinticFade))
+}
+
+func TestPerlinCubicDefaultInterpolator(t *testing.T) {
+	noisers := map[string][2]Noiser{
+		"seeded": {NewPerlinCubic(seed, nil), NewPerlinCubic(seed, CatmullRomInterpolator)},
+		"hashed": {NewPerlinCubicHashed(seed, nil), NewPerlinCubicHashed(seed, CatmullRomInterpolator)},
+	}
+	for name, pair := range noisers {
+		xs, ys := batchPoints(64)
+		for i := range xs {
+			if got, want := pair[0].Noise(xs[i], ys[i]), pair[1].Noise(xs[i], ys[i]); got != want {
+				t.Fatalf("%s: nil interpolator at %v, %v = %v, want %v", name, xs[i], ys[i], got, want)
+			}
+		}
+	}
+}
+
+func TestPerlinCubicMatchesCatmullRom(t *testing.T) {
+	cubic := NewPerlinCubic(seed, CatmullRomInterpolator)
+	hermite := NewPerlinCubic(seed, HermiteInterpolator(0))
+	catmullRom := NewSmoothPerlinCatmullRom(seed)
+	xs, ys := batchPoints(imageDimension)
+	for i := range xs {
+		want := catmullRom.Noise(xs[i], ys[i])
+		if got := cubic.Noise(xs[i], ys[i]); got != want {
+			t.Fatalf("Noise(%v, %v) = %v, want %v", xs[i], ys[i], got, want)
+		}
+		if got := hermite.Noise(xs[i], ys[i]); math.Abs(got-want) > 1e-12 {
+			t.Fatalf("Hermite Noise(%v, %v) = %v, want %v", xs[i], ys[i], got, want)
+		}
+	}
+}
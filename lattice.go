@@ -0,0 +1,248 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// DefaultTableSize is the size of the gradient lookup table used by
+// generators that are not given a table size.
+const DefaultTableSize = hashSize2D
+
+// Gradient is a gradient vector assigned to points of the noise lattice.
+// Gradients are usually unit vectors.
+type Gradient struct {
+	X, Y float64
+}
+
+// DefaultGradients returns the twelve evenly spaced unit vectors used by
+// generators that are not given a gradient set.
+func DefaultGradients() []Gradient {
+	g := make([]Gradient, len(gradient2D))
+	for i, p := range gradient2D {
+		g[i] = Gradient{p.X, p.Y}
+	}
+	return g
+}
+
+// EvenGradients returns n unit vectors evenly spaced around the unit circle,
+// beginning with (1, 0). More directions reduce the directional bias of the
+// noise.
+func EvenGradients(n int) []Gradient {
+	g := make([]Gradient, n)
+	delta := 2 * math.Pi / float64(n)
+	for i := range g {
+		g[i] = Gradient{math.Cos(delta * float64(i)), math.Sin(delta * float64(i))}
+	}
+	return g
+}
+
+// RandomGradients returns n unit vectors pointing in random directions chosen
+// using the seed. The directions come from the same stable stream as
+// NewStablePermutationTable, so they never change for a seed.
+func RandomGradients(n int, seed int64) []Gradient {
+	rng := newSplitMix64(seed)
+	g := make([]Gradient, n)
+	for i := range g {
+		angle := rng.Float64() * (2 * math.Pi)
+		g[i] = Gradient{math.Cos(angle), math.Sin(angle)}
+	}
+	return g
+}
+
+// PermutationTable is the seeded hash table that generators use to randomly
+// assign gradients to lattice points. It is immutable once built, so a single
+// table may be shared by any number of generators, including across
+// goroutines. Sharing a table avoids allocating a copy for every generator
+// built from the same seed.
+type PermutationTable struct {
+	hash []int
+	size int
+}
+
+// NewPermutationTable builds the table for the seed. The noise of generators
+// using the table repeats every size units along each axis. A non-positive
+// size uses DefaultTableSize. Generators constructed from a seed use the same
+// table as NewPermutationTable(seed, DefaultTableSize).
+func NewPermutationTable(seed int64, size int) *PermutationTable {
+	return newPermutationTable(rand.New(rand.NewSource(seed)), size)
+}
+
+// NewStablePermutationTable builds a table for the seed whose contents are
+// defined by this package rather than by math/rand, so that noise built on it
+// stays the same across Go versions. Entry i of the table is the i-th value of
+// a SplitMix64 stream whose initial state is the seed, multiplied by size and
+// shifted right by 64 bits. A non-positive size uses DefaultTableSize.
+func NewStablePermutationTable(seed int64, size int) *PermutationTable {
+	return newPermutationTable(newSplitMix64(seed), size)
+}
+
+// intner is a source of random table entries.
+type intner interface {
+	Intn(n int) int
+}
+
+// newPermutationTable fills a table with entries drawn from rng. The entries
+// are repeated once so that lookups offset by another entry need no wrapping.
+func newPermutationTable(rng intner, size int) *PermutationTable {
+	if size <= 0 {
+		size = DefaultTableSize
+	}
+	p := &PermutationTable{
+		hash: make([]int, 0, size*2),
+		size: size,
+	}
+	for i := 0; i < size; i++ {
+		p.hash = append(p.hash, rng.Intn(size))
+	}
+	p.hash = append(p.hash, p.hash...)
+	return p
+}
+
+// Size returns the number of entries in the table, which is the period of
+// the noise along each axis.
+func (p *PermutationTable) Size() int {
+	return p.size
+}
+
+// lattice randomly assigns gradients to the integer points of the plane using
+// a permutation table. The hash slice is shared with the table. A lattice
+// without a table instead hashes each point together with the seed, and never
+// repeats.
+type lattice struct {
+	hash      []int
+	size      int
+	seed      uint64
+	gradients []point2D
+}
+
+// newHashedLattice builds a lattice that needs no table, using the default
+// gradients.
+func newHashedLattice(seed int64) *lattice {
+	return &lattice{
+		seed:      uint64(seed),
+		gradients: gradient2D,
+	}
+}
+
+// newLatticeFromTable builds a lattice that shares the permutation table. An
+// empty gradient set uses the default gradients.
+func newLatticeFromTable(table *PermutationTable, gradients []Gradient) *lattice {
+	l := &lattice{
+		hash:      table.hash,
+		size:      table.size,
+		gradients: gradient2D,
+	}
+	if len(gradients) > 0 {
+		l.gradients = make([]point2D, len(gradients))
+		for i, g := range gradients {
+			l.gradients[i] = point2D{g.X, g.Y}
+		}
+	}
+	return l
+}
+
+// wrap wraps a lattice coordinate into the range of the hash table. Without a
+// table, coordinates are left as they are.
+func (l *lattice) wrap(i int) int {
+	if l.hash == nil {
+		return i
+	}
+	i = intMod(i, l.size)
+	for i < 0 {
+		i += l.size
+	}
+	return i
+}
+
+// gradient looks up the gradient for wrapped lattice coordinates. Each
+// coordinate may exceed the wrapped range by at most one.
+func (l *lattice) gradient(x, y int) point2D {
+	if l.hash == nil {
+		return l.gradients[hashPoint(l.seed, x, y)%uint64(len(l.gradients))]
+	}
+	return l.gradients[intMod(l.hash[x+l.hash[y]], len(l.gradients))]
+}
+
+// row returns the part of the gradient hash that depends only on the wrapped
+// y coordinate, so that a row of gradients can be looked up with rowGradient
+// while hashing y once.
+func (l *lattice) row(y int) uint64 {
+	if l.hash == nil {
+		return hashRound(uint64(int64(y)))
+	}
+	return uint64(l.hash[y])
+}
+
+// rowGradient looks up the gradient at the wrapped x coordinate of the row
+// returned by row. It gives the same gradient as gradient.
+func (l *lattice) rowGradient(x int, row uint64) point2D {
+	if l.hash == nil {
+		return l.gradients[hashLanes(l.seed, hashRound(uint64(int64(x))), row)%uint64(len(l.gradients))]
+	}
+	return l.gradients[intMod(l.hash[x+int(row)], len(l.gradients))]
+}
+
+// farGradient looks up the gradient for wrapped lattice coordinates that may
+// exceed the wrapped range by more than one.
+func (l *lattice) farGradient(x, y int) point2D {
+	if l.hash == nil {
+		return l.gradient(x, y)
+	}
+	hashY := l.hash[intMod(y, len(l.hash))]
+	return l.gradients[intMod(l.hash[intMod(x+hashY, len(l.hash))], len(l.gradients))]
+}
+
+// Multiplicative constants from xxHash64, used by hashPoint.
+const (
+	hashPrime1 uint64 = 11400714785074694791
+	hashPrime2 uint64 = 14029467366897019727
+	hashPrime3 uint64 = 1609587929392839161
+)
+
+// hashPoint mixes a seed and a lattice point into a well distributed 64-bit
+// value. It follows the xxHash64 round and avalanche steps. Its output is part
+// of the definition of the hashed generators and must never change.
+func hashPoint(seed uint64, x, y int) uint64 {
+	return hashLanes(seed, hashRound(uint64(int64(x))), hashRound(uint64(int64(y))))
+}
+
+// hashLanes finishes hashPoint from the scrambled x and y lanes.
+func hashLanes(seed, x, y uint64) uint64 {
+	h := seed + hashPrime3
+	h ^= x
+	h = bits.RotateLeft64(h, 27)*hashPrime1 + hashPrime2
+	h ^= y
+	h = bits.RotateLeft64(h, 27)*hashPrime1 + hashPrime2
+	h ^= h >> 33
+	h *= hashPrime2
+	h ^= h >> 29
+	h *= hashPrime3
+	h ^= h >> 32
+	return h
+}
+
+// hashRound scrambles a single input lane as xxHash64 does.
+func hashRound(v uint64) uint64 {
+	v *= hashPrime2
+	v = bits.RotateLeft64(v, 31)
+	return v * hashPrime1
+}
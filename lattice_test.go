@@ -0,0 +1,146 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDefaultGradients(t *testing.T) {
+	g := DefaultGradients()
+	if len(g) != len(gradient2D) {
+		t.Fatalf("got %d default gradients, want %d", len(g), len(gradient2D))
+	}
+	for i := range g {
+		if g[i].X != gradient2D[i].X || g[i].Y != gradient2D[i].Y {
+			t.Errorf("default gradient %d is %v, want %v", i, g[i], gradient2D[i])
+		}
+	}
+	assertSameNoise(t, "perlin(42)", NewPerlinWithGradients(seed, DefaultTableSize, DefaultGradients(), nil))
+	assertSameNoise(t, "simplex(42)", NewSimplexWithGradients(seed, 0, nil))
+}
+
+func TestRandomGradientsAreUnitVectors(t *testing.T) {
+	for _, g := range RandomGradients(256, seed) {
+		if mag := math.Hypot(g.X, g.Y); math.Abs(mag-1) > 1e-12 {
+			t.Fatalf("gradient %v has magnitude %v", g, mag)
+		}
+	}
+}
+
+func TestTableSizeSetsPeriod(t *testing.T) {
+	const size = 16
+	generators := map[string]Noiser{
+		"perlin":  NewPerlinWithGradients(seed, size, EvenGradients(16), nil),
+		"simplex": NewSimplexWithGradients(seed, size, RandomGradients(8, seed)),
+		"cubic":   NewPerlinCubicWithGradients(seed, size, EvenGradients(256), CatmullRomInterpolator),
+	}
+	for name, n := range generators {
+		for i := 0; i < 50; i++ {
+			x := float64(i)*0.731 - 3
+			y := float64(i)*0.377 + 1
+			if a, b := n.Noise(x, y), n.Noise(x+size, y-size); math.Abs(a-b) > 1e-9 {
+				t.Errorf("%s: Noise(%v, %v) = %v does not repeat, got %v", name, x, y, a, b)
+			}
+		}
+	}
+}
+
+func TestSharedPermutationTable(t *testing.T) {
+	table := NewPermutationTable(seed, DefaultTableSize)
+	assertSameNoise(t, "perlin(42)", NewPerlinFromTable(table, nil, nil))
+	assertSameNoise(t, "simplex(42)", NewSimplexFromTable(table, nil))
+	shared, smooth := NewPerlinCatmullRomFromTable(table), NewSmoothPerlinCatmullRom(seed)
+	xs, ys := batchPoints(imageDimension)
+	for i := range xs {
+		if got, want := shared.Noise(xs[i], ys[i]), smooth.Noise(xs[i], ys[i]); got != want {
+			t.Fatalf("PerlinCatmullRom: Noise(%v, %v) = %v, want %v", xs[i], ys[i], got, want)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		NewPerlinFromTable(table, nil, nil)
+	})
+	if allocs > 2 {
+		t.Errorf("got %v allocations constructing from a shared table", allocs)
+	}
+}
+
+func TestHashedLatticeDoesNotRepeat(t *testing.T) {
+	generators := map[string]Noiser{
+		"perlin":  NewPerlinHashed(seed),
+		"simplex": NewSimplexHashed(seed),
+		"cubic":   NewPerlinCubicHashed(seed, CatmullRomInterpolator),
+		"worley":  NewWorley(seed),
+	}
+	offsets := [][2]float64{
+		{DefaultTableSize, 0},
+		{0, DefaultTableSize},
+		{DefaultTableSize, DefaultTableSize},
+		{3 * DefaultTableSize, 0},
+		{0, 7 * DefaultTableSize},
+		{-5 * DefaultTableSize, 2 * DefaultTableSize},
+		{1 << 20, 1 << 24},
+	}
+	for name, n := range generators {
+		for _, offset := range offsets {
+			// Nearly every point should differ from its shifted copy by more
+			// than the rounding of the shift, so that a lattice that repeats
+			// in only some of its cells is caught.
+			repeats := 0
+			for i := 0; i < 200; i++ {
+				x := float64(i)*0.731 - 2.9
+				y := float64(i)*0.377 + 1.1
+				if math.Abs(n.Noise(x, y)-n.Noise(x+offset[0], y+offset[1])) < 1e-9 {
+					repeats++
+				}
+			}
+			if repeats > 2 {
+				t.Errorf("%s repeats at %d of 200 points %v units away", name, repeats, offset)
+			}
+		}
+	}
+}
+
+func TestHashedLatticeUsesFullSeed(t *testing.T) {
+	// math/rand reduces seeds modulo 2^31-1, so these seeds would share a
+	// permutation table.
+	a := NewPerlinHashed(1)
+	b := NewPerlinHashed(1 + (1<<31 - 1))
+	if a.Noise(0.5, 0.5) == b.Noise(0.5, 0.5) && a.Noise(3.5, 7.5) == b.Noise(3.5, 7.5) {
+		t.Error("seeds differing only above 31 bits give the same noise")
+	}
+}
+
+func TestHashPointIsStable(t *testing.T) {
+	tests := []struct {
+		seed uint64
+		x, y int
+		want uint64
+	}{
+		{0, 0, 0, 18069697567746195929},
+		{42, 1, -1, 12059597457565438524},
+		{1<<63 + 5, -7000000, 123456789, 10147958492844170435},
+	}
+	for _, test := range tests {
+		if got := hashPoint(test.seed, test.x, test.y); got != test.want {
+			t.Errorf("hashPoint(%d, %d, %d) = %d, want %d", test.seed, test.x, test.y, got, test.want)
+		}
+	}
+}
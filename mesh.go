@@ -0,0 +1,302 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MeshOptions controls the terrain meshes written by WriteOBJ, WriteSTL and
+// WritePLY.
+type MeshOptions struct {
+	// VerticalScale multiplies the noise to give the height of each vertex.
+	// Zero leaves the noise unscaled, as a scale of one does.
+	VerticalScale float64
+	// Normals adds a normal to each vertex, from the gradient of the noise.
+	Normals bool
+	// UVs adds texture coordinates from zero to one across the grid.
+	UVs bool
+	// SkirtDepth, when positive, closes the mesh into a solid for 3D
+	// printing. Walls hang from the edges of the terrain down to a flat base
+	// SkirtDepth below its lowest vertex.
+	SkirtDepth float64
+}
+
+// meshVertex is a vertex of a terrain mesh with its normal and texture
+// coordinates.
+type meshVertex struct {
+	x, y, z    float64
+	nx, ny, nz float64
+	u, v       float64
+}
+
+// mesh is an indexed triangle mesh. Triangles wind counterclockwise when seen
+// from outside.
+type mesh struct {
+	vertices  []meshVertex
+	triangles [][3]int
+}
+
+// newMesh samples the noise on a grid and triangulates it. The mesh is Z-up:
+// the vertices lie at the sampled points of the XY plane, raised by the
+// scaled noise.
+func newMesh(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, opts *MeshOptions) (*mesh, error) {
+	if numberSamplesX < 2 || numberSamplesY < 2 {
+		return nil, errors.New("mesh needs at least two samples along each axis")
+	}
+	if opts == nil {
+		opts = &MeshOptions{}
+	}
+	verticalScale := opts.VerticalScale
+	if verticalScale == 0 {
+		verticalScale = 1
+	}
+	terrain, err := sampleGrid(ctx, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, 3, func(out []float64, x, y float64) {
+		out[0] = noiser.Noise(x, y)
+		if opts.Normals {
+			out[1], out[2] = NoiseGradient(noiser, x, y)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Place the vertices at the points that sampleGrid sampled.
+	xs := gridCoords(minX, numberSamplesX, noiseSampleDelta)
+	ys := gridCoords(minY, numberSamplesY, noiseSampleDelta)
+	m := &mesh{}
+	lowest := math.Inf(1)
+	for row, samples := range terrain {
+		for col := 0; col < numberSamplesX; col++ {
+			vertex := meshVertex{
+				x: xs[col],
+				y: ys[row],
+				z: samples[3*col] * verticalScale,
+				u: float64(col) / float64(numberSamplesX-1),
+				v: float64(row) / float64(numberSamplesY-1),
+			}
+			vertex.nx, vertex.ny, vertex.nz = surfaceNormal(samples[3*col+1], samples[3*col+2], verticalScale)
+			lowest = math.Min(lowest, vertex.z)
+			m.vertices = append(m.vertices, vertex)
+		}
+	}
+	for row := 0; row < numberSamplesY-1; row++ {
+		for col := 0; col < numberSamplesX-1; col++ {
+			a := row*numberSamplesX + col
+			b := a + 1
+			c := a + numberSamplesX
+			d := c + 1
+			m.triangles = append(m.triangles, [3]int{a, b, d}, [3]int{a, d, c})
+		}
+	}
+	if opts.SkirtDepth > 0 {
+		m.addSkirt(numberSamplesX, numberSamplesY, lowest-opts.SkirtDepth)
+	}
+	return m, nil
+}
+
+// addSkirt hangs walls from the edges of the grid down to the base height and
+// closes them with a flat base.
+func (m *mesh) addSkirt(numberSamplesX, numberSamplesY int, base float64) {
+	// Walk the edges counterclockwise seen from above, with the outward
+	// normal of each edge.
+	edges := []struct {
+		start, step, count int
+		nx, ny             float64
+	}{
+		{0, 1, numberSamplesX, 0, -1},
+		{numberSamplesX - 1, numberSamplesX, numberSamplesY, 1, 0},
+		{numberSamplesX*numberSamplesY - 1, -1, numberSamplesX, 0, 1},
+		{numberSamplesX * (numberSamplesY - 1), -numberSamplesX, numberSamplesY, -1, 0},
+	}
+	var ring []meshVertex
+	for _, edge := range edges {
+		first := len(m.vertices)
+		for i := 0; i < edge.count; i++ {
+			top := m.vertices[edge.start+i*edge.step]
+			top.nx, top.ny, top.nz = edge.nx, edge.ny, 0
+			bottom := top
+			bottom.z = base
+			m.vertices = append(m.vertices, top, bottom)
+			if i < edge.count-1 {
+				ring = append(ring, bottom)
+			}
+		}
+		for i := 0; i < edge.count-1; i++ {
+			top := first + 2*i
+			bottom := top + 1
+			nextTop := top + 2
+			nextBottom := top + 3
+			m.triangles = append(m.triangles, [3]int{top, bottom, nextBottom}, [3]int{top, nextBottom, nextTop})
+		}
+	}
+
+	// Fan the base from its center, so that it meets the walls at every
+	// vertex of their bottom edges.
+	center := meshVertex{z: base, u: 0.5, v: 0.5}
+	for _, v := range ring {
+		center.x += v.x / float64(len(ring))
+		center.y += v.y / float64(len(ring))
+	}
+	first := len(m.vertices)
+	m.vertices = append(m.vertices, center)
+	for _, v := range ring {
+		v.nx, v.ny, v.nz = 0, 0, -1
+		m.vertices = append(m.vertices, v)
+	}
+	m.vertices[first].nz = -1
+	for i := range ring {
+		next := (i+1)%len(ring) + first + 1
+		m.triangles = append(m.triangles, [3]int{first, next, first + 1 + i})
+	}
+}
+
+// faceNormal returns the unit normal of the triangle from its winding.
+func (m *mesh) faceNormal(t [3]int) (x, y, z float64) {
+	a, b, c := m.vertices[t[0]], m.vertices[t[1]], m.vertices[t[2]]
+	ux, uy, uz := b.x-a.x, b.y-a.y, b.z-a.z
+	vx, vy, vz := c.x-a.x, c.y-a.y, c.z-a.z
+	x = uy*vz - uz*vy
+	y = uz*vx - ux*vz
+	z = ux*vy - uy*vx
+	if length := math.Sqrt(x*x + y*y + z*z); length > 0 {
+		x, y, z = x/length, y/length, z/length
+	}
+	return
+}
+
+// WriteOBJ samples the noise on a grid like WriteGreyImagePngNormalized and
+// writes it as a triangulated Wavefront OBJ terrain mesh. The mesh is Z-up,
+// with its vertices at the sampled points of the XY plane raised by the noise.
+// A nil MeshOptions uses a vertical scale of one with no normals, UVs or
+// skirt.
+func WriteOBJ(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, opts *MeshOptions) error {
+	m, err := newMesh(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, opts)
+	if err != nil {
+		return err
+	}
+	normals := opts != nil && opts.Normals
+	uvs := opts != nil && opts.UVs
+
+	bw := bufio.NewWriter(w)
+	for _, v := range m.vertices {
+		fmt.Fprintf(bw, "v %g %g %g\n", v.x, v.y, v.z)
+	}
+	if uvs {
+		for _, v := range m.vertices {
+			fmt.Fprintf(bw, "vt %g %g\n", v.u, v.v)
+		}
+	}
+	if normals {
+		for _, v := range m.vertices {
+			fmt.Fprintf(bw, "vn %g %g %g\n", v.nx, v.ny, v.nz)
+		}
+	}
+	for _, t := range m.triangles {
+		bw.WriteString("f")
+		for _, i := range t {
+			// OBJ indices start at one.
+			i++
+			switch {
+			case uvs && normals:
+				fmt.Fprintf(bw, " %d/%d/%d", i, i, i)
+			case uvs:
+				fmt.Fprintf(bw, " %d/%d", i, i)
+			case normals:
+				fmt.Fprintf(bw, " %d//%d", i, i)
+			default:
+				fmt.Fprintf(bw, " %d", i)
+			}
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// WriteSTL writes the terrain mesh described by WriteOBJ as binary STL. STL
+// stores the normal of each triangle rather than of each vertex and has no
+// texture coordinates, so the Normals and UVs options are ignored.
+func WriteSTL(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, opts *MeshOptions) error {
+	m, err := newMesh(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	var header [80]byte
+	copy(header[:], "noise terrain")
+	bw.Write(header[:])
+	binary.Write(bw, binary.LittleEndian, uint32(len(m.triangles)))
+	for _, t := range m.triangles {
+		nx, ny, nz := m.faceNormal(t)
+		values := []float32{float32(nx), float32(ny), float32(nz)}
+		for _, i := range t {
+			v := m.vertices[i]
+			values = append(values, float32(v.x), float32(v.y), float32(v.z))
+		}
+		binary.Write(bw, binary.LittleEndian, values)
+		// The attribute byte count is unused.
+		binary.Write(bw, binary.LittleEndian, uint16(0))
+	}
+	return bw.Flush()
+}
+
+// WritePLY writes the terrain mesh described by WriteOBJ as binary
+// little-endian PLY, with float vertex properties x, y and z, then nx, ny and
+// nz with the Normals option, then s and t with the UVs option.
+func WritePLY(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, opts *MeshOptions) error {
+	m, err := newMesh(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, opts)
+	if err != nil {
+		return err
+	}
+	normals := opts != nil && opts.Normals
+	uvs := opts != nil && opts.UVs
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ply\nformat binary_little_endian 1.0\nelement vertex %d\n", len(m.vertices))
+	bw.WriteString("property float x\nproperty float y\nproperty float z\n")
+	if normals {
+		bw.WriteString("property float nx\nproperty float ny\nproperty float nz\n")
+	}
+	if uvs {
+		bw.WriteString("property float s\nproperty float t\n")
+	}
+	fmt.Fprintf(bw, "element face %d\nproperty list uchar int vertex_indices\nend_header\n", len(m.triangles))
+
+	for _, v := range m.vertices {
+		values := []float32{float32(v.x), float32(v.y), float32(v.z)}
+		if normals {
+			values = append(values, float32(v.nx), float32(v.ny), float32(v.nz))
+		}
+		if uvs {
+			values = append(values, float32(v.u), float32(v.v))
+		}
+		binary.Write(bw, binary.LittleEndian, values)
+	}
+	for _, t := range m.triangles {
+		bw.WriteByte(3)
+		binary.Write(bw, binary.LittleEndian, [3]int32{int32(t[0]), int32(t[1]), int32(t[2])})
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,201 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// meshVolume returns the volume enclosed by the mesh, which is positive when
+// its triangles wind outward.
+func meshVolume(m *mesh) float64 {
+	volume := 0.0
+	for _, t := range m.triangles {
+		a, b, c := m.vertices[t[0]], m.vertices[t[1]], m.vertices[t[2]]
+		volume += (a.x*(b.y*c.z-b.z*c.y) - a.y*(b.x*c.z-b.z*c.x) + a.z*(b.x*c.y-b.y*c.x)) / 6
+	}
+	return volume
+}
+
+func TestMeshSkirtIsClosed(t *testing.T) {
+	opts := &MeshOptions{VerticalScale: 2, SkirtDepth: 0.5}
+	m, err := newMesh(context.Background(), constantNoise(1), 0, 0, 3, 4, 1, 0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := meshVolume(m); math.Abs(v-3) > 1e-9 {
+		t.Errorf("volume = %v, want 3", v)
+	}
+
+	m, err = newMesh(context.Background(), NewPerlin(seed), startCorner, startCorner, 9, 7, sampleStep, 0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every edge of a closed mesh is shared by two triangles that walk it in
+	// opposite directions.
+	position := func(i int) string {
+		v := m.vertices[i]
+		return fmt.Sprintf("%.9g,%.9g,%.9g", v.x, v.y, v.z)
+	}
+	edges := make(map[[2]string]int)
+	for _, tri := range m.triangles {
+		for i := range tri {
+			edges[[2]string{position(tri[i]), position(tri[(i+1)%3])}]++
+		}
+	}
+	for edge, count := range edges {
+		if count != 1 || edges[[2]string{edge[1], edge[0]}] != 1 {
+			t.Fatalf("edge %v is walked %d times and reversed %d times", edge, count, edges[[2]string{edge[1], edge[0]}])
+		}
+	}
+	if meshVolume(m) <= 0 {
+		t.Error("skirted mesh winds inward")
+	}
+}
+
+func TestMeshNormals(t *testing.T) {
+	opts := &MeshOptions{VerticalScale: 3, Normals: true}
+	n := NewPerlin(seed)
+	m, err := newMesh(context.Background(), n, startCorner, startCorner, 5, 5, sampleStep, 0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range m.vertices {
+		dx, dy := n.NoiseGradient(v.x, v.y)
+		nx, ny, nz := surfaceNormal(dx, dy, 3)
+		if math.Abs(v.nx-nx) > 1e-9 || math.Abs(v.ny-ny) > 1e-9 || math.Abs(v.nz-nz) > 1e-9 {
+			t.Fatalf("vertex %d normal = %v, %v, %v, want %v, %v, %v", i, v.nx, v.ny, v.nz, nx, ny, nz)
+		}
+	}
+	for _, tri := range m.triangles {
+		if _, _, z := m.faceNormal(tri); z <= 0 {
+			t.Fatal("surface triangle faces down")
+		}
+	}
+}
+
+func TestMeshZeroVerticalScale(t *testing.T) {
+	m, err := newMesh(context.Background(), constantNoise(0.5), 0, 0, 2, 2, 1, 0, &MeshOptions{Normals: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z := m.vertices[0].z; z != 0.5 {
+		t.Errorf("height = %v, want 0.5", z)
+	}
+	if nz := m.vertices[0].nz; nz != 1 {
+		t.Errorf("flat normal z = %v, want 1", nz)
+	}
+}
+
+// bitsNoise gives different values for points that differ in the last bit.
+type bitsNoise struct{}
+
+func (bitsNoise) Noise(x, y float64) float64 {
+	return float64((math.Float64bits(x)*31 + math.Float64bits(y)) % (1 << 53))
+}
+
+func TestMeshVerticesAtSampledPoints(t *testing.T) {
+	// The height of a vertex is the noise at its own x and y, even where
+	// adding up a delta of 0.01 would drift from minX + col*delta.
+	const minX, minY, delta = 0.3, -2.7, 0.01
+	m, err := newMesh(context.Background(), bitsNoise{}, minX, minY, 120, 90, delta, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range m.vertices {
+		if want := (bitsNoise{}).Noise(v.x, v.y); v.z != want {
+			t.Fatalf("vertex %d at %v, %v has height %v, want %v", i, v.x, v.y, v.z, want)
+		}
+	}
+}
+
+func TestWriteOBJ(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteOBJ(context.Background(), &buffer, rowNoise{}, 0, 0, 3, 3, 1, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 9+8 {
+		t.Fatalf("wrote %d lines, want %d", len(lines), 9+8)
+	}
+	if lines[3] != "v 0 1 1" || lines[9] != "f 1 2 5" {
+		t.Errorf("got vertex %q and face %q", lines[3], lines[9])
+	}
+
+	buffer.Reset()
+	opts := &MeshOptions{VerticalScale: 1, Normals: true, UVs: true}
+	if err := WriteOBJ(context.Background(), &buffer, rowNoise{}, 0, 0, 3, 3, 1, 0, opts); err != nil {
+		t.Fatal(err)
+	}
+	obj := buffer.String()
+	if !strings.Contains(obj, "\nvt 0.5 0.5\n") || !strings.Contains(obj, "\nf 1/1/1 2/2/2 5/5/5\n") {
+		t.Errorf("missing texture coordinates or normals:\n%s", obj)
+	}
+	if got := strings.Count(obj, "\nvn "); got != 9 {
+		t.Errorf("wrote %d normals, want 9", got)
+	}
+}
+
+func TestWriteSTL(t *testing.T) {
+	var buffer bytes.Buffer
+	opts := &MeshOptions{VerticalScale: 1, SkirtDepth: 1}
+	if err := WriteSTL(context.Background(), &buffer, NewSimplex(seed), 0, 0, 4, 4, 0.5, 0, opts); err != nil {
+		t.Fatal(err)
+	}
+	// Nine cells, twelve walls and a base fanned around the twelve
+	// vertices of its edge.
+	const triangles = 9*2 + 12*2 + 12
+	if got := binary.LittleEndian.Uint32(buffer.Bytes()[80:]); got != triangles {
+		t.Errorf("triangle count = %d, want %d", got, triangles)
+	}
+	if buffer.Len() != 84+50*triangles {
+		t.Errorf("wrote %d bytes, want %d", buffer.Len(), 84+50*triangles)
+	}
+}
+
+func TestWritePLY(t *testing.T) {
+	var buffer bytes.Buffer
+	opts := &MeshOptions{VerticalScale: 1, Normals: true, UVs: true}
+	if err := WritePLY(context.Background(), &buffer, NewPerlin(seed), 0, 0, 3, 2, 0.5, 0, opts); err != nil {
+		t.Fatal(err)
+	}
+	ply := buffer.String()
+	end := strings.Index(ply, "end_header\n") + len("end_header\n")
+	header := ply[:end]
+	for _, want := range []string{"element vertex 6\n", "property float nz\n", "property float t\n", "element face 4\n"} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header is missing %q:\n%s", want, header)
+		}
+	}
+	if got, want := buffer.Len()-end, 6*8*4+4*13; got != want {
+		t.Errorf("wrote %d bytes of data, want %d", got, want)
+	}
+}
+
+func TestMeshErrors(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteOBJ(context.Background(), &buffer, rowNoise{}, 0, 0, 1, 5, 1, 0, nil); err == nil {
+		t.Error("no error for a grid one sample wide")
+	}
+}
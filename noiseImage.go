@@ -0,0 +1,93 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"image"
+	"image/color"
+)
+
+var _ image.Image = &NoiseImage{}
+
+// NoiseImage is a greyscale image.Image whose pixels are sampled from a Noiser
+// when they are read, so it can be passed to image/draw or an encoder without
+// sampling the whole image first. Pixels are sampled every time they are
+// read, so slow noises should be drawn into a buffer when read many times.
+//
+// Like WriteGreyImagePng, the bottom row of the image is sampled at the
+// minimum Y and rows go up in noise space. Pixel (x, y) samples the noise at
+// minX + x*delta and minY + (height-1-y)*delta, which are the same points
+// that WriteGreyImagePngNormalized samples.
+type NoiseImage struct {
+	noiser        Noiser
+	minX, minY    float64
+	width, height int
+	delta         float64
+	normalizer    Normalizer
+}
+
+// NewNoiseImage creates an image of the given size sampling the noise from
+// (minX, minY) in increments of delta. The Normalizer maps the samples to
+// shades of grey, and values normalized outside zero to one are clamped. A nil
+// Normalizer maps -1 to black and 1 to white, since the samples are not known
+// in advance.
+func NewNoiseImage(noiser Noiser, minX, minY float64, width, height int, delta float64, normalizer Normalizer) *NoiseImage {
+	if normalizer == nil {
+		normalizer = &FixedRange{Min: -1, Max: 1}
+	}
+	return &NoiseImage{
+		noiser:     noiser,
+		minX:       minX,
+		minY:       minY,
+		width:      width,
+		height:     height,
+		delta:      delta,
+		normalizer: normalizer,
+	}
+}
+
+// ColorModel returns the greyscale color model.
+func (n *NoiseImage) ColorModel() color.Model {
+	return color.Gray16Model
+}
+
+// Bounds returns the size of the image, with its origin at zero.
+func (n *NoiseImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, n.width, n.height)
+}
+
+// At returns the shade of grey of the pixel.
+func (n *NoiseImage) At(x, y int) color.Color {
+	return n.Gray16At(x, y)
+}
+
+// Gray16At samples the noise at the pixel and returns its shade of grey.
+// Pixels outside the bounds are black.
+func (n *NoiseImage) Gray16At(x, y int) color.Gray16 {
+	if !(image.Point{x, y}.In(n.Bounds())) {
+		return color.Gray16{}
+	}
+	v := n.noiser.Noise(n.minX+float64(float64(x)*n.delta), n.minY+float64(float64(n.height-1-y)*n.delta))
+	frac := clampUnit(n.normalizer.Normalize(v))
+	return color.Gray16{uint16(linearInterpolation(0, 65535, frac))}
+}
+
+// Opaque reports that every pixel is opaque.
+func (n *NoiseImage) Opaque() bool {
+	return true
+}
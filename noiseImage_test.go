@@ -0,0 +1,102 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+func TestNoiseImageMatchesWriter(t *testing.T) {
+	const delta = 0.01
+	n := NewPerlin(seed)
+	normalizer := &FixedRange{Min: -1, Max: 1}
+	var want, got bytes.Buffer
+	if err := WriteGreyImagePngNormalized(context.Background(), &want, n, -3, 2, 24, 16, delta, 0, normalizer); err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(&got, NewNoiseImage(n, -3, 2, 24, 16, delta, normalizer)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Error("encoded NoiseImage differs from WriteGreyImagePngNormalized")
+	}
+}
+
+// pointNoise records the last point it sampled.
+type pointNoise struct {
+	x, y float64
+}
+
+func (p *pointNoise) Noise(x, y float64) float64 {
+	p.x, p.y = x, y
+	return 0
+}
+
+func TestNoiseImageSamplesWriterPoints(t *testing.T) {
+	const delta = 0.01
+	ctx := context.Background()
+	xs, err := sampleRows(ctx, columnNoise{}, -3, 2, 300, 200, delta, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ys, err := sampleRows(ctx, rowNoise{}, -3, 2, 300, 200, delta, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &pointNoise{}
+	img := NewNoiseImage(p, -3, 2, 300, 200, delta, nil)
+	for y := range xs {
+		for x := range xs[y] {
+			// The image has the first row at the bottom.
+			img.At(x, 200-1-y)
+			if p.x != xs[y][x] || p.y != ys[y][x] {
+				t.Fatalf("NoiseImage sampled (%v, %v), want (%v, %v)", p.x, p.y, xs[y][x], ys[y][x])
+			}
+		}
+	}
+}
+
+func TestNoiseImageDraw(t *testing.T) {
+	src := NewNoiseImage(rowNoise{}, 0, 1, 2, 3, 1, &FixedRange{Min: 1, Max: 3})
+	if got := src.Bounds(); got != image.Rect(0, 0, 2, 3) {
+		t.Fatalf("Bounds() = %v", got)
+	}
+	dst := image.NewGray16(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), src, image.Point{}, draw.Src)
+	for i, want := range []uint16{0, 32767, 65535} {
+		if got := dst.Gray16At(1, 2-i).Y; got != want {
+			t.Errorf("row %d shade = %d, want %d", i, got, want)
+		}
+	}
+	if got := src.Gray16At(2, 0); got != (color.Gray16{}) {
+		t.Errorf("pixel outside the bounds = %v, want black", got)
+	}
+}
+
+func TestNoiseImageDefaultNormalizer(t *testing.T) {
+	img := NewNoiseImage(constantNoise(0), 0, 0, 1, 1, 1, nil)
+	if got := img.Gray16At(0, 0).Y; got != 32767 {
+		t.Errorf("zero noise shade = %d, want 32767", got)
+	}
+}
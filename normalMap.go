@@ -0,0 +1,75 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// WriteNormalMapPng writes a tangent-space normal map of the noise, treated as
+// a heightfield, out to a 16-bit PNG image. The noise is sampled like
+// WriteGreyImagePngNormalized, and its slopes come from NoiseGradient, so
+// generators with analytic gradients give exact normals.
+//
+// The height is in the same units as the noise coordinates, multiplied by the
+// strength. The red channel points right and the green channel points up the
+// image, which is the OpenGL convention; DirectX engines should invert green.
+func WriteNormalMapPng(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, strength float64) error {
+	img, err := NewNormalMap(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, strength)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// NewNormalMap samples a tangent-space normal map of the noise into an image,
+// as described by WriteNormalMapPng.
+func NewNormalMap(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, strength float64) (*image.NRGBA64, error) {
+	if numberSamplesX <= 0 || numberSamplesY <= 0 {
+		return nil, errInvalidDimensions
+	}
+	slopes, err := sampleGrid(ctx, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, 2, func(out []float64, x, y float64) {
+		out[0], out[1] = NoiseGradient(noiser, x, y)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA64(image.Rect(0, 0, numberSamplesX, numberSamplesY))
+	for y, row := range slopes {
+		for x := 0; x < numberSamplesX; x++ {
+			img.SetNRGBA64(x, numberSamplesY-y-1, normalColor(-strength*row[2*x], -strength*row[2*x+1], 1))
+		}
+	}
+	return img, nil
+}
+
+// normalColor normalizes the vector and encodes each of its components from
+// -1 to 1 as a channel from zero to full.
+func normalColor(x, y, z float64) color.NRGBA64 {
+	length := math.Sqrt(x*x + y*y + z*z)
+	encode := func(v float64) uint16 {
+		return uint16(math.Round(linearInterpolation(0, 65535, clampUnit((v/length+1)/2))))
+	}
+	return color.NRGBA64{R: encode(x), G: encode(y), B: encode(z), A: 65535}
+}
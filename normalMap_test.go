@@ -0,0 +1,95 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestNoiseGradientMatchesFiniteDifferences(t *testing.T) {
+	const tolerance = 1e-6
+	octave := NewOctaveNoise(0.5)
+	octave.AddOctave(NewPerlin(seed))
+	octave.AddOctave(NewSimplex(seed))
+	noisers := map[string]GradientNoiser{
+		"Perlin":         NewPerlin(seed),
+		"hashed Perlin":  NewPerlinHashed(seed),
+		"quintic Perlin": NewPerlinWithFade(seed, QuinticFade),
+		"cubic Perlin":   NewPerlinWithFade(seed, CubicFade),
+		"cosine Perlin":  NewPerlinWithFade(seed, CosineFade),
+		"Simplex":        NewSimplex(seed),
+		"OctaveNoise":    octave,
+	}
+	for name, n := range noisers {
+		for i := 0; i < 200; i++ {
+			// Stay off the lattice lines, where the second derivatives of the
+			// cubic and cosine fades jump and central differences are off.
+			x := startCorner + 0.05 + float64(i)*sampleStep*1.3
+			y := startCorner + 0.05 + float64(i%17)*sampleStep*5.1
+			dx, dy := n.NoiseGradient(x, y)
+			wantDX, wantDY := finiteDifferenceGradient(n, x, y)
+			if math.Abs(dx-wantDX) > tolerance || math.Abs(dy-wantDY) > tolerance {
+				t.Fatalf("%s: gradient at %v, %v = %v, %v, want %v, %v", name, x, y, dx, dy, wantDX, wantDY)
+			}
+		}
+	}
+}
+
+func TestNoiseGradientFallback(t *testing.T) {
+	dx, dy := NoiseGradient(rowNoise{}, 3, 4)
+	if math.Abs(dx) > 1e-9 || math.Abs(dy-1) > 1e-9 {
+		t.Errorf("gradient of y = %v, %v, want 0, 1", dx, dy)
+	}
+}
+
+func TestNewNormalMap(t *testing.T) {
+	img, err := NewNormalMap(context.Background(), constantNoise(0.3), 0, 0, 2, 2, 1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.NRGBA64At(1, 1), (color.NRGBA64{32768, 32768, 65535, 65535}); got != want {
+		t.Errorf("flat normal = %v, want %v", got, want)
+	}
+
+	// Height rising up the image tilts the normals down the image.
+	img, err = NewNormalMap(context.Background(), rowNoise{}, 0, 0, 2, 2, 1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.NRGBA64At(0, 0), (color.NRGBA64{32768, 9597, 55938, 65535}); got != want {
+		t.Errorf("sloped normal = %v, want %v", got, want)
+	}
+}
+
+func TestWriteNormalMapPng(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteNormalMapPng(context.Background(), &buffer, NewPerlin(seed), startCorner, startCorner, 8, 8, sampleStep, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteNormalMapPng(context.Background(), &buffer, NewPerlin(seed), 0, 0, 0, 8, 1, 0, 1); err == nil {
+		t.Error("no error for an empty image")
+	}
+}
@@ -0,0 +1,93 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+)
+
+var _ Normalizer = &Stats{}
+var _ Normalizer = &FixedRange{}
+
+// Normalizer maps noise values onto zero to one, where zero is black and one
+// is white, for writing images. Using the same Normalizer for every tile of a
+// tiled set of images gives the tiles matching brightness.
+type Normalizer interface {
+	Normalize(v float64) float64
+}
+
+// FixedRange normalizes a fixed range of noise values, such as -1 to 1, so
+// that the mapping does not depend on the samples. Values outside the range
+// are clamped to it.
+type FixedRange struct {
+	Min, Max float64
+}
+
+// Normalize maps the value onto zero to one, where Min is zero and Max is
+// one. Every value is zero when Min is not below Max.
+func (f *FixedRange) Normalize(v float64) float64 {
+	if f.Max <= f.Min {
+		return 0
+	}
+	return clampUnit((v - f.Min) / (f.Max - f.Min))
+}
+
+// SamplePercentileRange samples the noise over a region and returns the range
+// between the low and high percentiles of the samples, which are between zero
+// and one hundred. Percentiles such as 2 and 98 ignore rare extremes that
+// would otherwise darken the rest of an image. The percentiles interpolate
+// between the two closest samples. The region is sampled like SampleStats.
+func SamplePercentileRange(noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, low, high float64) (*FixedRange, error) {
+	if numberSamplesX <= 0 || numberSamplesY <= 0 {
+		return nil, errInvalidDimensions
+	}
+	if !(0 <= low && low <= high && high <= 100) {
+		return nil, errors.New("percentiles must be ordered between 0 and 100")
+	}
+	rows, err := sampleRows(context.Background(), noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, 0)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, 0, numberSamplesX*numberSamplesY)
+	for _, row := range rows {
+		values = append(values, row...)
+	}
+	sort.Float64s(values)
+	return &FixedRange{
+		Min: percentile(values, low),
+		Max: percentile(values, high),
+	}, nil
+}
+
+// percentile returns the pth percentile of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	pos := float64(p / 100 * float64(len(sorted)-1))
+	i := int(math.Floor(pos))
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return linearInterpolation(sorted[i], sorted[i+1], pos-float64(i))
+}
+
+// clampUnit clamps the value to zero to one.
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,145 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestFixedRangeNormalize(t *testing.T) {
+	f := &FixedRange{Min: -1, Max: 1}
+	tests := []struct {
+		v, want float64
+	}{
+		{-1, 0},
+		{0, 0.5},
+		{1, 1},
+		{-3, 0},
+		{2, 1},
+	}
+	for _, test := range tests {
+		if got := f.Normalize(test.v); got != test.want {
+			t.Errorf("Normalize(%v) = %v, want %v", test.v, got, test.want)
+		}
+	}
+	if got := (&FixedRange{Min: 1, Max: 1}).Normalize(1); got != 0 {
+		t.Errorf("empty range Normalize(1) = %v, want 0", got)
+	}
+}
+
+func TestSamplePercentileRange(t *testing.T) {
+	tests := []struct {
+		low, high float64
+		want      FixedRange
+	}{
+		{0, 100, FixedRange{1, 3}},
+		{50, 50, FixedRange{2, 2}},
+		{10, 90, FixedRange{1, 3}},
+		{30, 70, FixedRange{1.5, 2.5}},
+	}
+	for _, test := range tests {
+		got, err := SamplePercentileRange(rowNoise{}, 0, 1, 2, 3, 1, test.low, test.high)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != test.want {
+			t.Errorf("percentiles %v to %v = %+v, want %+v", test.low, test.high, *got, test.want)
+		}
+	}
+	for _, bad := range [][2]float64{{-1, 50}, {60, 40}, {0, 101}} {
+		if _, err := SamplePercentileRange(rowNoise{}, 0, 1, 2, 3, 1, bad[0], bad[1]); err == nil {
+			t.Errorf("no error for percentiles %v", bad)
+		}
+	}
+}
+
+func decodeGrey(t *testing.T, b []byte) *image.Gray16 {
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img.(*image.Gray16)
+}
+
+func TestWriteGreyImagePngNormalizedTiles(t *testing.T) {
+	const delta = 0.01
+	n := NewPerlin(seed)
+	normalizer := &FixedRange{Min: -1, Max: 1}
+	var whole, left, right bytes.Buffer
+	ctx := context.Background()
+	if err := WriteGreyImagePngNormalized(ctx, &whole, n, 0, 0, 16, 8, delta, 0, normalizer); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteGreyImagePngNormalized(ctx, &left, n, 0, 0, 8, 8, delta, 0, normalizer); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteGreyImagePngNormalized(ctx, &right, n, 8*delta, 0, 8, 8, delta, 0, normalizer); err != nil {
+		t.Fatal(err)
+	}
+	wholeImg := decodeGrey(t, whole.Bytes())
+	leftImg := decodeGrey(t, left.Bytes())
+	rightImg := decodeGrey(t, right.Bytes())
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if wholeImg.Gray16At(x, y) != leftImg.Gray16At(x, y) {
+				t.Errorf("left tile differs at %d, %d", x, y)
+			}
+			if wholeImg.Gray16At(x+8, y) != rightImg.Gray16At(x, y) {
+				t.Errorf("right tile differs at %d, %d", x, y)
+			}
+		}
+	}
+}
+
+func TestSampleRowsTiles(t *testing.T) {
+	const delta = 0.01
+	ctx := context.Background()
+	whole, err := sampleRows(ctx, columnNoise{}, -3, 0, 1000, 1, delta, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tile, err := sampleRows(ctx, columnNoise{}, -3+500*delta, 0, 500, 1, delta, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The tile begins one rounding away from the whole grid, and must not
+	// drift further.
+	for x, v := range tile[0] {
+		if want := whole[0][500+x]; math.Abs(v-want) > 1e-15 {
+			t.Fatalf("tile sampled x = %v, want %v", v, want)
+		}
+	}
+}
+
+func TestWriteGreyImagePngNormalizedClamps(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteGreyImagePngNormalized(context.Background(), &buffer, rowNoise{}, 0, 1, 1, 3, 1, 0, &FixedRange{Min: 1.5, Max: 2.5}); err != nil {
+		t.Fatal(err)
+	}
+	img := decodeGrey(t, buffer.Bytes())
+	for i, want := range []uint16{0, 32767, 65535} {
+		if got := img.Gray16At(0, 2-i).Y; got != want {
+			t.Errorf("row %d shade = %d, want %d", i, got, want)
+		}
+	}
+}
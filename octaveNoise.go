@@ -17,7 +17,10 @@
 
 package noise
 
-var _ Noiser = &OctaveNoise{}
+import "sync"
+
+var _ BatchNoiser = &OctaveNoise{}
+var _ GradientNoiser = &OctaveNoise{}
 
 // OctaveNoise uses other Noisers to create more noises composed on one another
 // using constant gain and lacunarity.
@@ -35,6 +38,22 @@ func NewOctaveNoise(persistence float64) *OctaveNoise {
 	}
 }
 
+// NoiserConstructor constructs a Noiser from a seed, such as a function
+// calling NewPerlin.
+type NoiserConstructor func(seed int64) Noiser
+
+// NewSeededOctaveNoise creates an octave-based noise with the given
+// persistence and number of octaves. Each octave is constructed with the seed
+// derived from seed.Derive("octave", i), so that the octaves are not
+// correlated with each other.
+func NewSeededOctaveNoise(persistence float64, octaves int, seed Seed, construct NoiserConstructor) *OctaveNoise {
+	o := NewOctaveNoise(persistence)
+	for i := 0; i < octaves; i++ {
+		o.AddOctave(construct(seed.Derive("octave", i).Int64()))
+	}
+	return o
+}
+
 // AddOctave adds the Noiser. Persistence is applied in the order they are
 // added. Noisers added later will have a higher sampling frequency but a lower
 // amplitude if the persistence was less than one.
@@ -49,10 +68,74 @@ func (o *OctaveNoise) Noise(x, y float64) float64 {
 	result := 0.0
 	cumulativeAmp := 0.0
 	for _, octave := range o.octaves {
-		result += octave.Noise(x*frequency, y*frequency) * amplitude
+		result += float64(octave.Noise(x*frequency, y*frequency) * amplitude)
 		frequency *= 2
 		cumulativeAmp += amplitude
 		amplitude *= o.persistence
 	}
 	return result
 }
+
+// NoiseGradient computes the gradient of the octave noise from the gradients
+// of its octaves, each found as described by NoiseGradient.
+func (o *OctaveNoise) NoiseGradient(x, y float64) (dx, dy float64) {
+	frequency := 1.0
+	amplitude := 1.0
+	for _, octave := range o.octaves {
+		octaveDX, octaveDY := NoiseGradient(octave, x*frequency, y*frequency)
+		dx += float64(octaveDX * frequency * amplitude)
+		dy += float64(octaveDY * frequency * amplitude)
+		frequency *= 2
+		amplitude *= o.persistence
+	}
+	return
+}
+
+// octaveScratch holds the scaled coordinates and samples of the octaves in
+// NoiseBatch, so that batches reuse their memory.
+var octaveScratch = sync.Pool{
+	New: func() interface{} {
+		return new([]float64)
+	},
+}
+
+// NoiseBatch generates noise for many points. Each octave is evaluated for all
+// of the points at once, so octaves that are BatchNoisers are sampled in bulk.
+// The first octave is sampled straight into out, and the others share a
+// pooled buffer.
+func (o *OctaveNoise) NoiseBatch(xs, ys, out []float64) {
+	if len(o.octaves) == 0 {
+		for i := range out {
+			out[i] = 0
+		}
+		return
+	}
+	n := len(out)
+	NoiseBatch(o.octaves[0], xs[:n], ys[:n], out)
+	if len(o.octaves) == 1 {
+		return
+	}
+
+	scratch := octaveScratch.Get().(*[]float64)
+	defer octaveScratch.Put(scratch)
+	if cap(*scratch) < 3*n {
+		*scratch = make([]float64, 3*n)
+	}
+	buffer := (*scratch)[:3*n]
+	scaledX, scaledY, octaveOut := buffer[:n], buffer[n:2*n], buffer[2*n:]
+
+	frequency := 2.0
+	amplitude := o.persistence
+	for _, octave := range o.octaves[1:] {
+		for i := range out {
+			scaledX[i] = xs[i] * frequency
+			scaledY[i] = ys[i] * frequency
+		}
+		NoiseBatch(octave, scaledX, scaledY, octaveOut)
+		for i := range out {
+			out[i] += float64(octaveOut[i] * amplitude)
+		}
+		frequency *= 2
+		amplitude *= o.persistence
+	}
+}
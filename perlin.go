@@ -17,38 +17,80 @@
 
 package noise
 
-import (
-	"math/rand"
-)
-
-var _ Noiser = &Perlin{}
+var _ BatchNoiser = &Perlin{}
+var _ GridNoiser = &Perlin{}
+var _ GradientNoiser = &Perlin{}
 
 // Perlin implements simple Perlin noise using a fading function whose second
 // derivative is zero at the interpolation boundaries. This results in a
-// smoother visualization.
+// smoother visualization. Other fading functions may be used instead.
 type Perlin struct {
-	rng  *rand.Rand
-	hash []int
+	lattice *lattice
+	fade    FadeFunc
+	// fadeDerivative is the derivative of the fading function, or nil when
+	// the Fade has none.
+	fadeDerivative FadeFunc
 }
 
 // NewPerlin constructs a new Perlin noise with the given seed. Multiple
 // instances constructed from the same seed will return the same noise values
 // for the same inputs.
 func NewPerlin(seed int64) *Perlin {
-	s := &Perlin{
-		rng:  rand.New(rand.NewSource(seed)),
-		hash: make([]int, 0, hashSize2D*2),
+	return NewPerlinWithFade(seed, nil)
+}
+
+// NewPerlinWithFade constructs a new Perlin noise with the given seed that
+// interpolates between the lattice gradients using the Fade. The same seed
+// gives the same lattice as NewPerlin. A nil Fade uses QuinticFade.
+func NewPerlinWithFade(seed int64, fade *Fade) *Perlin {
+	return NewPerlinWithGradients(seed, DefaultTableSize, nil, fade)
+}
+
+// NewPerlinWithGradients constructs a new Perlin noise whose lattice assigns
+// gradients from the given set using a hash table of the given size. The noise
+// repeats every tableSize units along each axis. A non-positive table size
+// uses DefaultTableSize, no gradients uses DefaultGradients, and a nil Fade
+// uses QuinticFade.
+func NewPerlinWithGradients(seed int64, tableSize int, gradients []Gradient, fade *Fade) *Perlin {
+	return NewPerlinFromTable(NewPermutationTable(seed, tableSize), gradients, fade)
+}
+
+// NewPerlinFromTable constructs a new Perlin noise that shares the permutation
+// table, so that many generators need only one table. No gradients uses
+// DefaultGradients and a nil Fade uses QuinticFade.
+func NewPerlinFromTable(table *PermutationTable, gradients []Gradient, fade *Fade) *Perlin {
+	if fade == nil {
+		fade = QuinticFade
+	}
+	return &Perlin{
+		lattice:        newLatticeFromTable(table, gradients),
+		fade:           fade.fade,
+		fadeDerivative: fade.derivative,
 	}
-	s.init()
-	return s
 }
 
-// Constructs the internal hash used to generate the perlin noise.
-func (s *Perlin) init() {
-	for i := 0; i < hashSize2D; i++ {
-		s.hash = append(s.hash, s.rng.Intn(hashSize2D))
+// NewPerlinVersion constructs a new Perlin noise with the given seed whose
+// values are frozen at the algorithm version. Version1 gives the same noise as
+// NewPerlin.
+func NewPerlinVersion(seed int64, version Version) (*Perlin, error) {
+	table, err := version.permutationTable(seed)
+	if err != nil {
+		return nil, err
+	}
+	return NewPerlinFromTable(table, nil, nil), nil
+}
+
+// NewPerlinHashed constructs a new Perlin noise that assigns gradients by
+// hashing each lattice point with the seed instead of using a permutation
+// table. It needs no table, never repeats, and every 64-bit seed gives
+// different noise. It gives different values than NewPerlin for the same
+// seed.
+func NewPerlinHashed(seed int64) *Perlin {
+	return &Perlin{
+		lattice:        newHashedLattice(seed),
+		fade:           QuinticFade.fade,
+		fadeDerivative: QuinticFade.derivative,
 	}
-	s.hash = append(s.hash, s.hash...)
 }
 
 // Noise generates simple Perlin noise.
@@ -59,27 +101,145 @@ func (s *Perlin) Noise(x, y float64) float64 {
 	relX := x - float64(x0)
 	relY := y - float64(y0)
 
-	x0 = intMod(x0, hashSize2D)
-	for x0 < 0 {
-		x0 += hashSize2D
+	grad00, grad10, grad01, grad11 := s.cellGradients(x0, y0)
+	return perlinCell(grad00, grad10, grad01, grad11, relX, relY, s.fade)
+}
+
+// NoiseGradient computes the gradient of the Perlin noise. It is exact when
+// the Fade has a derivative, and estimated by finite differences otherwise.
+func (s *Perlin) NoiseGradient(x, y float64) (dx, dy float64) {
+	if s.fadeDerivative == nil {
+		return finiteDifferenceGradient(s, x, y)
+	}
+	x0 := intFloor(x)
+	y0 := intFloor(y)
+
+	relX := x - float64(x0)
+	relY := y - float64(y0)
+
+	grad00, grad10, grad01, grad11 := s.cellGradients(x0, y0)
+	noise00 := grad00.DotFloat64(relX, relY)
+	noise10 := grad10.DotFloat64(relX-1, relY)
+	noise01 := grad01.DotFloat64(relX, relY-1)
+	noise11 := grad11.DotFloat64(relX-1, relY-1)
+
+	fadeX := s.fade(relX)
+	fadeY := s.fade(relY)
+	slopeX := s.fadeDerivative(relX)
+	slopeY := s.fadeDerivative(relY)
+
+	// Differentiate both interpolations along x, then the one along y.
+	noiseX0 := linearInterpolation(noise00, noise10, fadeX)
+	noiseX1 := linearInterpolation(noise01, noise11, fadeX)
+	dxX0 := linearInterpolation(grad00.X, grad10.X, fadeX) + float64(slopeX*(noise10-noise00))
+	dxX1 := linearInterpolation(grad01.X, grad11.X, fadeX) + float64(slopeX*(noise11-noise01))
+	dyX0 := linearInterpolation(grad00.Y, grad10.Y, fadeX)
+	dyX1 := linearInterpolation(grad01.Y, grad11.Y, fadeX)
+
+	dx = linearInterpolation(dxX0, dxX1, fadeY)
+	dy = linearInterpolation(dyX0, dyX1, fadeY) + float64(slopeY*(noiseX1-noiseX0))
+	return
+}
+
+// NoiseBatch generates simple Perlin noise for many points. The gradients of
+// a lattice cell are reused for consecutive points that lie in the same cell.
+func (s *Perlin) NoiseBatch(xs, ys, out []float64) {
+	xs = xs[:len(out)]
+	ys = ys[:len(out)]
+	var grad00, grad10, grad01, grad11 point2D
+	cellX, cellY := 0, 0
+	for i := range out {
+		x0 := intFloor(xs[i])
+		y0 := intFloor(ys[i])
+		if i == 0 || x0 != cellX || y0 != cellY {
+			grad00, grad10, grad01, grad11 = s.cellGradients(x0, y0)
+			cellX, cellY = x0, y0
+		}
+		out[i] = perlinCell(grad00, grad10, grad01, grad11, xs[i]-float64(x0), ys[i]-float64(y0), s.fade)
+	}
+}
+
+// FillGrid samples Perlin noise on a regular grid as described by GridNoiser.
+// The lattice cells and fades of each column are computed once and stepped
+// incrementally, the hash of each row is computed once, and gradients are
+// reused while a row stays in one cell.
+func (s *Perlin) FillGrid(dst []float64, stride int, minX, minY, dx, dy float64) {
+	if stride <= 0 {
+		return
 	}
-	y0 = intMod(y0, hashSize2D)
-	for y0 < 0 {
-		y0 += hashSize2D
+	cols := make([]int, stride)
+	relXs := make([]float64, stride)
+	fadeXs := make([]float64, stride)
+	cellX := intFloor(minX)
+	relX := minX - float64(cellX)
+	for col := range cols {
+		if col > 0 {
+			cellX, relX = latticeStep(cellX, relX, dx)
+		}
+		cols[col] = s.lattice.wrap(cellX)
+		relXs[col] = relX
+		fadeXs[col] = s.fade(relX)
 	}
 
-	grad00 := intMod(s.hash[x0+s.hash[y0]], len(gradient2D))
-	grad10 := intMod(s.hash[x0+1+s.hash[y0]], len(gradient2D))
-	grad01 := intMod(s.hash[x0+s.hash[y0+1]], len(gradient2D))
-	grad11 := intMod(s.hash[x0+1+s.hash[y0+1]], len(gradient2D))
+	cellY := intFloor(minY)
+	relY := minY - float64(cellY)
+	for row := 0; row < len(dst)/stride; row++ {
+		if row > 0 {
+			cellY, relY = latticeStep(cellY, relY, dy)
+		}
+		y0 := s.lattice.wrap(cellY)
+		row0 := s.lattice.row(y0)
+		row1 := s.lattice.row(y0 + 1)
+		fadeY := s.fade(relY)
+
+		var grad00, grad10, grad01, grad11 point2D
+		x0 := 0
+		line := dst[row*stride : (row+1)*stride]
+		for col := range line {
+			if col == 0 || cols[col] != x0 {
+				x0 = cols[col]
+				grad00 = s.lattice.rowGradient(x0, row0)
+				grad10 = s.lattice.rowGradient(x0+1, row0)
+				grad01 = s.lattice.rowGradient(x0, row1)
+				grad11 = s.lattice.rowGradient(x0+1, row1)
+			}
+			relX := relXs[col]
+			noise00 := grad00.DotFloat64(relX, relY)
+			noise10 := grad10.DotFloat64(relX-1, relY)
+			noise01 := grad01.DotFloat64(relX, relY-1)
+			noise11 := grad11.DotFloat64(relX-1, relY-1)
+
+			noiseX0 := linearInterpolation(noise00, noise10, fadeXs[col])
+			noiseX1 := linearInterpolation(noise01, noise11, fadeXs[col])
+			line[col] = linearInterpolation(noiseX0, noiseX1, fadeY)
+		}
+	}
+}
 
-	noise00 := gradient2D[grad00].DotFloat64(relX, relY)
-	noise10 := gradient2D[grad10].DotFloat64(relX-1, relY)
-	noise01 := gradient2D[grad01].DotFloat64(relX, relY-1)
-	noise11 := gradient2D[grad11].DotFloat64(relX-1, relY-1)
+// cellGradients looks up the gradients at the four corners of the lattice
+// cell whose lower left corner is (x0, y0).
+func (s *Perlin) cellGradients(x0, y0 int) (grad00, grad10, grad01, grad11 point2D) {
+	x0 = s.lattice.wrap(x0)
+	y0 = s.lattice.wrap(y0)
+
+	grad00 = s.lattice.gradient(x0, y0)
+	grad10 = s.lattice.gradient(x0+1, y0)
+	grad01 = s.lattice.gradient(x0, y0+1)
+	grad11 = s.lattice.gradient(x0+1, y0+1)
+	return
+}
 
-	fadeX := fader(relX)
-	fadeY := fader(relY)
+// perlinCell interpolates the noise within a lattice cell from its corner
+// gradients and the point's position relative to the lower left corner, using
+// the fading function.
+func perlinCell(grad00, grad10, grad01, grad11 point2D, relX, relY float64, fade FadeFunc) float64 {
+	noise00 := grad00.DotFloat64(relX, relY)
+	noise10 := grad10.DotFloat64(relX-1, relY)
+	noise01 := grad01.DotFloat64(relX, relY-1)
+	noise11 := grad11.DotFloat64(relX-1, relY-1)
+
+	fadeX := fade(relX)
+	fadeY := fade(relY)
 
 	noiseX0 := linearInterpolation(noise00, noise10, fadeX)
 	noiseX1 := linearInterpolation(noise01, noise11, fadeX)
@@ -0,0 +1,77 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+var _ Noiser = &Perlin32{}
+var _ Noiser32 = &Perlin32{}
+
+// Perlin32 implements the same noise as Perlin using single precision
+// arithmetic. For the same seed and float32 inputs, its values are within
+// Float32Tolerance of the values of Perlin.
+type Perlin32 struct {
+	lattice *lattice
+}
+
+// NewPerlin32 constructs a new single precision Perlin noise with the given
+// seed. The seed produces the same lattice as NewPerlin.
+func NewPerlin32(seed int64) *Perlin32 {
+	return NewPerlin32FromTable(NewPermutationTable(seed, DefaultTableSize))
+}
+
+// NewPerlin32FromTable constructs a new single precision Perlin noise that
+// shares the permutation table, so that many generators need only one table.
+func NewPerlin32FromTable(table *PermutationTable) *Perlin32 {
+	return &Perlin32{
+		lattice: newLatticeFromTable(table, nil),
+	}
+}
+
+// Noise generates simple Perlin noise, converting to and from single
+// precision.
+func (s *Perlin32) Noise(x, y float64) float64 {
+	return float64(s.Noise32(float32(x), float32(y)))
+}
+
+// Noise32 generates simple Perlin noise in single precision.
+func (s *Perlin32) Noise32(x, y float32) float32 {
+	x0 := intFloor32(x)
+	y0 := intFloor32(y)
+
+	relX := x - float32(x0)
+	relY := y - float32(y0)
+
+	x0 = s.lattice.wrap(x0)
+	y0 = s.lattice.wrap(y0)
+
+	grad00 := gradient2D32[intMod(s.lattice.hash[x0+s.lattice.hash[y0]], len(gradient2D32))]
+	grad10 := gradient2D32[intMod(s.lattice.hash[x0+1+s.lattice.hash[y0]], len(gradient2D32))]
+	grad01 := gradient2D32[intMod(s.lattice.hash[x0+s.lattice.hash[y0+1]], len(gradient2D32))]
+	grad11 := gradient2D32[intMod(s.lattice.hash[x0+1+s.lattice.hash[y0+1]], len(gradient2D32))]
+
+	noise00 := grad00.DotFloat32(relX, relY)
+	noise10 := grad10.DotFloat32(relX-1, relY)
+	noise01 := grad01.DotFloat32(relX, relY-1)
+	noise11 := grad11.DotFloat32(relX-1, relY-1)
+
+	fadeX := fader32(relX)
+	fadeY := fader32(relY)
+
+	noiseX0 := linearInterpolation32(noise00, noise10, fadeX)
+	noiseX1 := linearInterpolation32(noise01, noise11, fadeX)
+	return linearInterpolation32(noiseX0, noiseX1, fadeY)
+}
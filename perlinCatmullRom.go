@@ -19,53 +19,84 @@ package noise
 
 import (
 	"math"
-	"math/rand"
 )
 
 var _ Noiser = &PerlinCatmullRom{}
 
-// PerlinCatmullRom creates Perlin noise using centripetal Catmull-Rom spline
-// interpolation. This is slow.
+// PerlinCatmullRom creates Perlin noise using Catmull-Rom spline
+// interpolation over a 4x4 neighborhood of gradients. This is slower than
+// Perlin.
 //
-// Warning: this contains visual gridline artifacts.
+// NewPerlinCatmullRom interpolates with centripetal splines sampled at a
+// fixed number of points, which leaves faint gridlines. NewSmoothPerlinCatmullRom
+// and NewPerlinCatmullRomFromTable interpolate with bicubic uniform splines
+// instead, so that the noise and its first derivatives are continuous across
+// lattice cell boundaries.
 type PerlinCatmullRom struct {
-	rng             *rand.Rand
+	lattice *lattice
+	// version is Version1 when the splines are centripetal ones sampled at
+	// splineCacheSize points, as in the first release.
+	version         Version
 	splineCacheSize int
-	hash            []int
 }
 
-// Constructs a new source of noise using a centripetal Catmull-Rom spline
-// interpolation, with splines having the given number of points. The seed is
-// used to ensure idential PerlinCatmullRoms will return the same noise values
-// for the same inputs.
-//
-// Warning: this contains visual gridline artifacts.
+// Constructs a new source of noise using a Catmull-Rom spline interpolation.
+// The seed is used to ensure idential PerlinCatmullRoms will return the same
+// noise values for the same inputs. The splineCacheSize determines the number
+// of points sampled along each centripetal spline: the more points, the
+// smoother the noise, but the longer each call takes.
 func NewPerlinCatmullRom(splineCacheSize int, seed int64) *PerlinCatmullRom {
-	s := &PerlinCatmullRom{
-		rng:             rand.New(rand.NewSource(seed)),
-		splineCacheSize: splineCacheSize,
-		hash:            make([]int, 0, hashSize2D*2),
-	}
-	s.init()
+	s := NewPerlinCatmullRomFromTable(NewPermutationTable(seed, DefaultTableSize))
+	s.version = Version1
+	s.splineCacheSize = splineCacheSize
 	return s
 }
 
-// init creates the Perlin noise gradient hash.
-func (s *PerlinCatmullRom) init() {
-	for i := 0; i < hashSize2D; i++ {
-		s.hash = append(s.hash, s.rng.Intn(hashSize2D))
+// NewSmoothPerlinCatmullRom constructs a new source of noise using a bicubic
+// uniform Catmull-Rom spline interpolation, which has no gridline artifacts.
+// The seed is used to ensure idential PerlinCatmullRoms will return the same
+// noise values for the same inputs.
+func NewSmoothPerlinCatmullRom(seed int64) *PerlinCatmullRom {
+	return NewPerlinCatmullRomFromTable(NewPermutationTable(seed, DefaultTableSize))
+}
+
+// NewPerlinCatmullRomFromTable constructs a new source of noise using a
+// bicubic uniform Catmull-Rom spline interpolation that shares the permutation
+// table, so that many generators need only one table.
+func NewPerlinCatmullRomFromTable(table *PermutationTable) *PerlinCatmullRom {
+	return &PerlinCatmullRom{
+		lattice: newLatticeFromTable(table, nil),
 	}
-	s.hash = append(s.hash, s.hash...)
+}
+
+// NewPerlinCatmullRomVersion constructs a new source of noise using a
+// Catmull-Rom spline interpolation whose values are frozen at the algorithm
+// version. Version1 gives the same noise as NewPerlinCatmullRom. Later
+// versions give bicubic uniform splines and ignore splineCacheSize.
+func NewPerlinCatmullRomVersion(splineCacheSize int, seed int64, version Version) (*PerlinCatmullRom, error) {
+	table, err := version.permutationTable(seed)
+	if err != nil {
+		return nil, err
+	}
+	s := NewPerlinCatmullRomFromTable(table)
+	if version == Version1 {
+		s.version = version
+		s.splineCacheSize = splineCacheSize
+	}
+	return s, nil
 }
 
 // hashIdx handles wrapping out of bounds indices when doing hash lookups.
 func (s *PerlinCatmullRom) hashIdx(idx int) int {
-	return s.hash[intMod(idx, len(s.hash))]
+	return s.lattice.hash[intMod(idx, len(s.lattice.hash))]
 }
 
 // Noise creates Perlin noise using Catmull-Rom spline interpolations, which is
-// considerably slower than the simple variant of Perlin noise.
+// slower than the simple variant of Perlin noise.
 func (s *PerlinCatmullRom) Noise(x, y float64) float64 {
+	if s.version == Version1 {
+		return s.sampledNoise(x, y)
+	}
 	x0 := intFloor(x)
 	y0 := intFloor(y)
 
@@ -73,14 +104,8 @@ func (s *PerlinCatmullRom) Noise(x, y float64) float64 {
 	relY := y - float64(y0)
 
 	// Shift (0,0) to be lower left of 4x4 point grid
-	x0 = intMod(x0-1, hashSize2D)
-	for x0 < 0 {
-		x0 += hashSize2D
-	}
-	y0 = intMod(y0-1, hashSize2D)
-	for y0 < 0 {
-		y0 += hashSize2D
-	}
+	x0 = s.lattice.wrap(x0 - 1)
+	y0 = s.lattice.wrap(y0 - 1)
 
 	grad00 := intMod(s.hashIdx(x0+s.hashIdx(y0)), len(gradient2D))
 	grad10 := intMod(s.hashIdx(x0+1+s.hashIdx(y0)), len(gradient2D))
@@ -133,20 +158,42 @@ func (s *PerlinCatmullRom) Noise(x, y float64) float64 {
 	noise23 := gradient2D[grad23].Dot(pt23)
 	noise33 := gradient2D[grad33].Dot(pt33)
 
-	c := newCentripetalCached(s.splineCacheSize, point2D{float64(x0), noise00}, point2D{float64(x0) + 1, noise10}, point2D{float64(x0) + 2, noise20}, point2D{float64(x0) + 3, noise30})
-	noiseX0 := c.InterpolateX(float64(x0) + 1 + relX)
-	noiseX0 = math.Max(-1, math.Min(1, noiseX0))
-	c = newCentripetalCached(s.splineCacheSize, point2D{float64(x0), noise01}, point2D{float64(x0) + 1, noise11}, point2D{float64(x0) + 2, noise21}, point2D{float64(x0) + 3, noise31})
-	noiseX1 := c.InterpolateX(float64(x0) + 1 + relX)
-	noiseX1 = math.Max(-1, math.Min(1, noiseX1))
-	c = newCentripetalCached(s.splineCacheSize, point2D{float64(x0), noise02}, point2D{float64(x0) + 1, noise12}, point2D{float64(x0) + 2, noise22}, point2D{float64(x0) + 3, noise32})
-	noiseX2 := c.InterpolateX(float64(x0) + 1 + relX)
-	noiseX2 = math.Max(-1, math.Min(1, noiseX2))
-	c = newCentripetalCached(s.splineCacheSize, point2D{float64(x0), noise03}, point2D{float64(x0) + 1, noise13}, point2D{float64(x0) + 2, noise23}, point2D{float64(x0) + 3, noise33})
-	noiseX3 := c.InterpolateX(float64(x0) + 1 + relX)
-	noiseX3 = math.Max(-1, math.Min(1, noiseX3))
-
-	c = newCentripetalCached(s.splineCacheSize, point2D{float64(y0), noiseX0}, point2D{float64(y0) + 1, noiseX1}, point2D{float64(y0) + 2, noiseX2}, point2D{float64(y0) + 3, noiseX3})
-	noise := c.InterpolateX(float64(y0) + 1 + relY)
-	return noise
+	noiseX0 := cubicCatmullRom(noise00, noise10, noise20, noise30, relX)
+	noiseX1 := cubicCatmullRom(noise01, noise11, noise21, noise31, relX)
+	noiseX2 := cubicCatmullRom(noise02, noise12, noise22, noise32, relX)
+	noiseX3 := cubicCatmullRom(noise03, noise13, noise23, noise33, relX)
+	return cubicCatmullRom(noiseX0, noiseX1, noiseX2, noiseX3, relY)
+}
+
+// sampledNoise creates noise as Version1 did, with a centripetal spline
+// through each row of the 4x4 grid and then a spline through the rows. The
+// splines are sampled as needed so that no memory is allocated.
+func (s *PerlinCatmullRom) sampledNoise(x, y float64) float64 {
+	x0 := intFloor(x)
+	y0 := intFloor(y)
+
+	relX := x - float64(x0)
+	relY := y - float64(y0)
+
+	x0 = s.lattice.wrap(x0 - 1)
+	y0 = s.lattice.wrap(y0 - 1)
+
+	var rows [4]float64
+	for j := range rows {
+		var pts [4]float64
+		for i := range pts {
+			grad := gradient2D[intMod(s.hashIdx(x0+i+s.hashIdx(y0+j)), len(gradient2D))]
+			pts[i] = grad.DotFloat64(relX-float64(i-1), relY-float64(j-1))
+		}
+		noise := s.interpolate(float64(x0), pts[0], pts[1], pts[2], pts[3], float64(x0)+1+relX)
+		rows[j] = math.Max(-1, math.Min(1, noise))
+	}
+	return s.interpolate(float64(y0), rows[0], rows[1], rows[2], rows[3], float64(y0)+1+relY)
+}
+
+// interpolate evaluates a centripetal Catmull-Rom spline through four values
+// spaced one unit apart, beginning at start, for the given position.
+func (s *PerlinCatmullRom) interpolate(start, v0, v1, v2, v3, at float64) float64 {
+	c := makeCatmullRom(0.5, point2D{start, v0}, point2D{start + 1, v1}, point2D{start + 2, v2}, point2D{start + 3, v3})
+	return c.SampledInterpolateX(s.splineCacheSize, at)
 }
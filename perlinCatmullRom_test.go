@@ -0,0 +1,127 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+// cachedCatmullRomNoise computes PerlinCatmullRom noise by building a
+// catmullRomCached for each of the five splines, as the first release did.
+func cachedCatmullRomNoise(s *PerlinCatmullRom, x, y float64) float64 {
+	x0 := intFloor(x)
+	y0 := intFloor(y)
+	relX := x - float64(x0)
+	relY := y - float64(y0)
+	x0 = s.lattice.wrap(x0 - 1)
+	y0 = s.lattice.wrap(y0 - 1)
+
+	var rows [4]point2D
+	for j := range rows {
+		var pts [4]point2D
+		for i := range pts {
+			grad := gradient2D[intMod(s.hashIdx(x0+i+s.hashIdx(y0+j)), len(gradient2D))]
+			pts[i] = point2D{float64(x0 + i), grad.DotFloat64(relX-float64(i-1), relY-float64(j-1))}
+		}
+		c := newCentripetalCached(s.splineCacheSize, pts[0], pts[1], pts[2], pts[3])
+		rows[j] = point2D{float64(y0 + j), math.Max(-1, math.Min(1, c.InterpolateX(float64(x0)+1+relX)))}
+	}
+	c := newCentripetalCached(s.splineCacheSize, rows[0], rows[1], rows[2], rows[3])
+	return c.InterpolateX(float64(y0) + 1 + relY)
+}
+
+func TestPerlinCatmullRomMatchesCached(t *testing.T) {
+	for _, size := range []int{splineCacheSize, 16} {
+		s := NewPerlinCatmullRom(size, seed)
+		xs, ys := batchPoints(imageDimension)
+		for i := range xs {
+			got := s.Noise(xs[i], ys[i])
+			if want := cachedCatmullRomNoise(s, xs[i], ys[i]); got != want {
+				t.Fatalf("size %d: Noise(%v, %v) = %v, want %v", size, xs[i], ys[i], got, want)
+			}
+		}
+	}
+}
+
+// derivativeJump returns the difference between the one-sided derivatives of
+// f at zero, estimated with finite differences.
+func derivativeJump(f func(float64) float64) float64 {
+	const h = 1e-6
+	left := (f(0) - f(-h)) / h
+	right := (f(h) - f(0)) / h
+	return math.Abs(right - left)
+}
+
+func TestSmoothPerlinCatmullRomC1Continuity(t *testing.T) {
+	const tolerance = 1e-3
+	s := NewSmoothPerlinCatmullRom(seed)
+	for cell := -20; cell <= 20; cell++ {
+		for _, frac := range []float64{0.1, 0.37, 0.5, 0.81} {
+			edge := float64(cell)
+			along := float64(cell) + frac
+			dx := derivativeJump(func(d float64) float64 { return s.Noise(edge+d, along) })
+			dy := derivativeJump(func(d float64) float64 { return s.Noise(along, edge+d) })
+			if dx > tolerance || dy > tolerance {
+				t.Errorf("derivative jumps by (%v, %v) at cell edge %v, %v along the edge", dx, dy, edge, along)
+			}
+		}
+	}
+}
+
+func TestPerlinCatmullRomAllocations(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *PerlinCatmullRom
+	}{
+		{"sampled", NewPerlinCatmullRom(splineCacheSize, seed)},
+		{"smooth", NewSmoothPerlinCatmullRom(seed)},
+	}
+	for _, test := range tests {
+		allocs := testing.AllocsPerRun(100, func() {
+			test.s.Noise(0.3, 0.7)
+		})
+		if allocs != 0 {
+			t.Errorf("%s: got %v allocations per call, want 0", test.name, allocs)
+		}
+	}
+}
+
+func BenchmarkPerlinCatmullRom(b *testing.B) {
+	s := NewPerlinCatmullRom(splineCacheSize, seed)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.Noise(startCorner+float64(i)*sampleStep, startCorner)
+	}
+}
+
+func BenchmarkPerlinCatmullRomCached(b *testing.B) {
+	s := NewPerlinCatmullRom(splineCacheSize, seed)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cachedCatmullRomNoise(s, startCorner+float64(i)*sampleStep, startCorner)
+	}
+}
+
+func BenchmarkSmoothPerlinCatmullRom(b *testing.B) {
+	s := NewSmoothPerlinCatmullRom(seed)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.Noise(startCorner+float64(i)*sampleStep, startCorner)
+	}
+}
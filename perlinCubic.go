@@ -0,0 +1,99 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+var _ Noiser = &PerlinCubic{}
+
+// PerlinCubic creates Perlin noise by interpolating the gradients of a 4x4
+// neighborhood of lattice points with a chosen cubic interpolator. Using
+// CatmullRomInterpolator gives the same noise as NewSmoothPerlinCatmullRom.
+type PerlinCubic struct {
+	lattice     *lattice
+	interpolate CubicInterpolator
+}
+
+// NewPerlinCubic constructs a new source of Perlin noise that uses the cubic
+// interpolator along both axes. The same seed gives the same lattice as
+// NewPerlin and NewSmoothPerlinCatmullRom. A nil interpolator uses
+// CatmullRomInterpolator.
+func NewPerlinCubic(seed int64, interpolator CubicInterpolator) *PerlinCubic {
+	return NewPerlinCubicWithGradients(seed, DefaultTableSize, nil, interpolator)
+}
+
+// NewPerlinCubicWithGradients constructs a new source of Perlin noise that
+// uses the cubic interpolator, and whose lattice assigns gradients from the
+// given set using a hash table of the given size. A non-positive table size
+// uses DefaultTableSize, no gradients uses DefaultGradients, and a nil
+// interpolator uses CatmullRomInterpolator.
+func NewPerlinCubicWithGradients(seed int64, tableSize int, gradients []Gradient, interpolator CubicInterpolator) *PerlinCubic {
+	return NewPerlinCubicFromTable(NewPermutationTable(seed, tableSize), gradients, interpolator)
+}
+
+// NewPerlinCubicFromTable constructs a new source of Perlin noise that uses the
+// cubic interpolator and shares the permutation table, so that many
+// generators need only one table. No gradients uses DefaultGradients and a
+// nil interpolator uses CatmullRomInterpolator.
+func NewPerlinCubicFromTable(table *PermutationTable, gradients []Gradient, interpolator CubicInterpolator) *PerlinCubic {
+	return newPerlinCubic(newLatticeFromTable(table, gradients), interpolator)
+}
+
+// NewPerlinCubicHashed constructs a new source of Perlin noise that uses the
+// cubic interpolator, and assigns gradients by hashing each lattice point with
+// the seed instead of using a permutation table. It needs no table, never
+// repeats, and every 64-bit seed gives different noise. A nil interpolator
+// uses CatmullRomInterpolator.
+func NewPerlinCubicHashed(seed int64, interpolator CubicInterpolator) *PerlinCubic {
+	return newPerlinCubic(newHashedLattice(seed), interpolator)
+}
+
+// newPerlinCubic interpolates the lattice with the cubic interpolator, or
+// with CatmullRomInterpolator when it is nil.
+func newPerlinCubic(l *lattice, interpolator CubicInterpolator) *PerlinCubic {
+	if interpolator == nil {
+		interpolator = CatmullRomInterpolator
+	}
+	return &PerlinCubic{
+		lattice:     l,
+		interpolate: interpolator,
+	}
+}
+
+// Noise creates Perlin noise using the cubic interpolator, first along each
+// row of the neighborhood and then across the rows.
+func (s *PerlinCubic) Noise(x, y float64) float64 {
+	x0 := intFloor(x)
+	y0 := intFloor(y)
+
+	relX := x - float64(x0)
+	relY := y - float64(y0)
+
+	// Shift (0,0) to be lower left of 4x4 point grid
+	x0 = s.lattice.wrap(x0 - 1)
+	y0 = s.lattice.wrap(y0 - 1)
+
+	var rows [4]float64
+	for j := range rows {
+		var noise [4]float64
+		for i := range noise {
+			grad := s.lattice.farGradient(x0+i, y0+j)
+			noise[i] = grad.DotFloat64(relX-float64(i-1), relY-float64(j-1))
+		}
+		rows[j] = s.interpolate(noise[0], noise[1], noise[2], noise[3], relX)
+	}
+	return s.interpolate(rows[0], rows[1], rows[2], rows[3], relY)
+}
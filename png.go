@@ -18,13 +18,20 @@
 package noise
 
 import (
+	"context"
 	"errors"
 	"image"
 	"image/color"
 	"image/png"
 	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
 )
 
+// errInvalidDimensions is returned when an image or region has no samples.
+var errInvalidDimensions = errors.New("invalid dimensions")
+
 // WriteGreyImagePng handles writing a Noiser out to a PNG image. It samples
 // the noise space in increments specified by noiseSampleDelta. The image is
 // generated using multiple goroutines to handle slower methods. It also
@@ -36,54 +43,183 @@ import (
 // noise sampling delta determines the distance between two sampled points in
 // noise space. Setting this parameter to one will yield a black image for
 // Perlin Noise.
+//
+// The normalization is that of Version1, which can miss the extreme samples.
+// Use WriteGreyImagePngVersion with LatestVersion for exact normalization.
 func WriteGreyImagePng(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64) error {
+	return WriteGreyImagePngVersion(w, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, Version1)
+}
+
+// WriteGreyImagePngVersion writes a Noiser out to a PNG image like
+// WriteGreyImagePng, normalizing the image as the algorithm version does.
+// Version1 divides by the range it finds exactly as the first release did, so
+// an image whose samples are all equal has undefined shades. Version2 and
+// later stretch the true minimum and maximum samples, as found by SampleStats,
+// to black and white, and write a black image when all samples are equal.
+func WriteGreyImagePngVersion(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, version Version) error {
+	return WriteGreyImagePngContext(context.Background(), w, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, 0, version)
+}
+
+// WriteGreyImagePngContext writes a Noiser out to a PNG image like
+// WriteGreyImagePngVersion, sampling the rows with the given number of worker
+// goroutines. A non-positive number of workers uses GOMAXPROCS workers.
+//
+// If the context is cancelled before every sample is taken, the workers stop
+// at their next sample and the context's error is returned without writing
+// anything. No goroutines are left running once it returns.
+func WriteGreyImagePngContext(ctx context.Context, w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, version Version) error {
 	if numberSamplesX <= 0 || numberSamplesY <= 0 {
-		return errors.New("invalid dimensions")
+		return errInvalidDimensions
+	}
+	if !version.valid() {
+		return errUnknownVersion
 	}
 
-	grays := make([][]float64, numberSamplesY)
-	noiseY := float64(minY)
-	done := make(chan struct{ MinValue, MaxValue float64 })
-	for y := 0; y < numberSamplesY; y++ {
-		go func(y int, noiseY float64) {
-			grays[y] = make([]float64, numberSamplesX)
-			noiseX := float64(minX)
-			minValue := 0.0
-			maxValue := 0.0
-			for x := 0; x < numberSamplesX; x++ {
-				grays[y][x] = noiser.Noise(noiseX, noiseY)
-				if x == 0 && y == 0 {
-					minValue = grays[y][x]
-					maxValue = minValue
-				} else if grays[y][x] > maxValue {
-					maxValue = grays[y][x]
-				} else if grays[y][x] < minValue {
-					minValue = grays[y][x]
-				}
-				noiseX += noiseSampleDelta
+	var grays [][]float64
+	var err error
+	if version == Version1 {
+		xs := accumulatedCoords(float64(minX), numberSamplesX, noiseSampleDelta)
+		ys := accumulatedCoords(float64(minY), numberSamplesY, noiseSampleDelta)
+		grays, err = sampleCoords(ctx, xs, ys, workers, 1, noiseSample(noiser))
+	} else {
+		grays, err = sampleRows(ctx, noiser, float64(minX), float64(minY), numberSamplesX, numberSamplesY, noiseSampleDelta, workers)
+	}
+	if err != nil {
+		return err
+	}
+
+	if version == Version1 {
+		minValue, maxValue := version1Range(grays)
+		return writeGrey(w, grays, func(v float64) float64 {
+			return (v - minValue) / (maxValue - minValue)
+		})
+	}
+	return writeGrey(w, grays, newStats(grays, 0).Normalize)
+}
+
+// WriteGreyImagePngNormalized writes a Noiser out to a PNG image like
+// WriteGreyImagePngContext, mapping the samples to shades of grey with the
+// Normalizer instead of stretching them to full greyscale. Values normalized
+// outside zero to one are clamped to black or white. A nil Normalizer
+// stretches the samples like LatestVersion. The minimum X and Y may be
+// fractional, so that tiles can continue where their neighbours end. Samples
+// are taken at the minimum plus each index times the delta, so the tiles do
+// not drift apart.
+func WriteGreyImagePngNormalized(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, normalizer Normalizer) error {
+	grays, normalize, err := sampleNormalized(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, normalizer)
+	if err != nil {
+		return err
+	}
+	return writeGrey(w, grays, normalize)
+}
+
+// WriteColorImagePng writes a Noiser out to a color PNG image, sampling it
+// like WriteGreyImagePngNormalized and coloring each normalized sample with
+// the ramp. A nil ramp is GrayscaleRamp.
+func WriteColorImagePng(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, normalizer Normalizer, ramp *ColorRamp) error {
+	grays, normalize, err := sampleNormalized(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, normalizer)
+	if err != nil {
+		return err
+	}
+	if ramp == nil {
+		ramp = GrayscaleRamp()
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, numberSamplesX, numberSamplesY))
+
+	for y, row := range grays {
+		for x, v := range row {
+			img.SetNRGBA(x, numberSamplesY-y-1, ramp.At(normalize(v)))
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// Channel is the noise of one channel of a packed image, such as a mask
+// texture, and its normalization. A nil Normalizer stretches the samples of
+// the channel like LatestVersion.
+type Channel struct {
+	Noiser     Noiser
+	Normalizer Normalizer
+}
+
+// NewRGBAImage samples up to four channels into the red, green, blue and alpha
+// channels of an image, in that order. Each channel is sampled like
+// WriteGreyImagePngNormalized and normalized on its own. Channels that are
+// missing or have a nil Noiser are zero, except alpha, which is opaque.
+func NewRGBAImage(ctx context.Context, channels []Channel, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int) (*image.NRGBA64, error) {
+	if len(channels) > 4 {
+		return nil, errors.New("more than four channels")
+	}
+	if numberSamplesX <= 0 || numberSamplesY <= 0 {
+		return nil, errInvalidDimensions
+	}
+	img := image.NewNRGBA64(image.Rect(0, 0, numberSamplesX, numberSamplesY))
+	// Each pixel is eight bytes, ending with the big-endian alpha.
+	for i := 6; i < len(img.Pix); i += 8 {
+		img.Pix[i] = 0xff
+		img.Pix[i+1] = 0xff
+	}
+
+	for c, channel := range channels {
+		if channel.Noiser == nil {
+			continue
+		}
+		grays, normalize, err := sampleNormalized(ctx, channel.Noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, channel.Normalizer)
+		if err != nil {
+			return nil, err
+		}
+		for y, row := range grays {
+			for x, v := range row {
+				shade := uint16(linearInterpolation(0, 65535, normalize(v)))
+				i := img.PixOffset(x, numberSamplesY-y-1) + c*2
+				img.Pix[i] = uint8(shade >> 8)
+				img.Pix[i+1] = uint8(shade)
 			}
-			done <- struct{ MinValue, MaxValue float64 }{minValue, maxValue}
-		}(y, noiseY)
-		noiseY += noiseSampleDelta
-	}
-	minValue := 0.0
-	maxValue := 0.0
-	for y := 0; y < numberSamplesY; y++ {
-		mm := <-done
-		if y == 0 {
-			minValue = mm.MinValue
-			maxValue = mm.MaxValue
-		} else if mm.MinValue < minValue {
-			minValue = mm.MinValue
-		} else if mm.MaxValue > maxValue {
-			maxValue = mm.MaxValue
 		}
 	}
-	img := image.NewGray16(image.Rect(0, 0, numberSamplesX, numberSamplesY))
+	return img, nil
+}
+
+// WriteRGBAImagePng writes up to four channels of noise out to a 16-bit PNG
+// image as described by NewRGBAImage.
+func WriteRGBAImagePng(ctx context.Context, w io.Writer, channels []Channel, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int) error {
+	img, err := NewRGBAImage(ctx, channels, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
 
-	for y := 0; y < numberSamplesY; y++ {
-		for x := 0; x < numberSamplesX; x++ {
-			frac := (grays[y][x] - minValue) / (maxValue - minValue)
+// sampleNormalized samples the rows of an image and returns them with a
+// function normalizing them onto zero to one. A nil Normalizer uses the
+// statistics of the samples.
+func sampleNormalized(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, normalizer Normalizer) ([][]float64, func(float64) float64, error) {
+	if numberSamplesX <= 0 || numberSamplesY <= 0 {
+		return nil, nil, errInvalidDimensions
+	}
+
+	grays, err := sampleRows(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers)
+	if err != nil {
+		return nil, nil, err
+	}
+	if normalizer == nil {
+		normalizer = newStats(grays, 0)
+	}
+	return grays, func(v float64) float64 {
+		return clampUnit(normalizer.Normalize(v))
+	}, nil
+}
+
+// writeGrey encodes the rows of samples as a PNG image with the first row at
+// the bottom, shading each sample by its normalized value.
+func writeGrey(w io.Writer, grays [][]float64, normalize func(float64) float64) error {
+	numberSamplesY := len(grays)
+	img := image.NewGray16(image.Rect(0, 0, len(grays[0]), numberSamplesY))
+
+	for y, row := range grays {
+		for x, v := range row {
+			frac := normalize(v)
 			uIntVal := uint16(linearInterpolation(0, 65535, frac))
 			shade := color.Gray16{uIntVal}
 			img.SetGray16(x, numberSamplesY-y-1, shade)
@@ -92,3 +228,138 @@ func WriteGreyImagePng(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, n
 
 	return png.Encode(w, img)
 }
+
+// version1Range reproduces the Version1 reduction of the samples. Only the
+// first row starts from its first sample; the others start from zero. A
+// sample is compared to the minimum only when it is not above the maximum,
+// and a row's maximum is used only when its minimum is not lower than the
+// rows before it.
+func version1Range(grays [][]float64) (minValue, maxValue float64) {
+	for y, row := range grays {
+		rowMin := 0.0
+		rowMax := 0.0
+		for x, v := range row {
+			if x == 0 && y == 0 {
+				rowMin = v
+				rowMax = rowMin
+			} else if v > rowMax {
+				rowMax = v
+			} else if v < rowMin {
+				rowMin = v
+			}
+		}
+		if y == 0 {
+			minValue = rowMin
+			maxValue = rowMax
+		} else if rowMin < minValue {
+			minValue = rowMin
+		} else if rowMax > maxValue {
+			maxValue = rowMax
+		}
+	}
+	return
+}
+
+// sampleRows samples the noise row by row using a pool of workers.
+func sampleRows(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int) ([][]float64, error) {
+	return sampleGrid(ctx, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, 1, noiseSample(noiser))
+}
+
+// noiseSample returns a sample function for sampleGrid that stores the noise.
+func noiseSample(noiser Noiser) func(out []float64, x, y float64) {
+	return func(out []float64, x, y float64) {
+		out[0] = noiser.Noise(x, y)
+	}
+}
+
+// sampleGrid calls sample for every point of a grid using a pool of workers,
+// as described by sampleCoords. The coordinates are found by gridCoords.
+func sampleGrid(ctx context.Context, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers, size int, sample func(out []float64, x, y float64)) ([][]float64, error) {
+	xs := gridCoords(minX, numberSamplesX, noiseSampleDelta)
+	ys := gridCoords(minY, numberSamplesY, noiseSampleDelta)
+	return sampleCoords(ctx, xs, ys, workers, size, sample)
+}
+
+// gridCoords returns the coordinates of count samples spaced delta apart,
+// beginning at min. Each coordinate is computed from its index rather than
+// accumulated, as NoiseImage does, so the coordinates do not drift and a tile
+// samples the points of the whole grid to within one rounding.
+func gridCoords(min float64, count int, delta float64) []float64 {
+	coords := make([]float64, count)
+	for i := range coords {
+		coords[i] = min + float64(float64(i)*delta)
+	}
+	return coords
+}
+
+// accumulatedCoords returns the coordinates of count samples spaced delta
+// apart the way Version1 images found them, by adding delta to the previous
+// coordinate.
+func accumulatedCoords(min float64, count int, delta float64) []float64 {
+	coords := make([]float64, count)
+	for i := range coords {
+		coords[i] = min
+		min += delta
+	}
+	return coords
+}
+
+// sampleCoords calls sample for every pair of coordinates using a pool of
+// workers, giving it the values of the point to fill in. Each row holds size
+// values per x coordinate. The results are the same for every number of
+// workers.
+func sampleCoords(ctx context.Context, xs, ys []float64, workers, size int, sample func(out []float64, x, y float64)) ([][]float64, error) {
+	numberSamplesY := len(ys)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > numberSamplesY {
+		workers = numberSamplesY
+	}
+
+	rows := make(chan int)
+	grays := make([][]float64, numberSamplesY)
+
+	// skipped is set when a worker abandons a row part way through.
+	var skipped int32
+	done := ctx.Done()
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				grays[y] = make([]float64, len(xs)*size)
+				for x, noiseX := range xs {
+					select {
+					case <-done:
+						atomic.StoreInt32(&skipped, 1)
+						return
+					default:
+					}
+					sample(grays[y][x*size:(x+1)*size], noiseX, ys[y])
+				}
+			}
+		}()
+	}
+	var err error
+	for y := 0; y < numberSamplesY && err == nil; y++ {
+		// Check first, since select picks randomly when a worker is also
+		// ready.
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case rows <- y:
+		case <-done:
+			err = ctx.Err()
+		}
+	}
+	close(rows)
+	wg.Wait()
+	// A context cancelled after the last sample does not spoil the grid.
+	if err == nil && atomic.LoadInt32(&skipped) != 0 {
+		err = ctx.Err()
+	}
+	return grays, err
+}
@@ -19,7 +19,9 @@ package noise
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
+	"sync/atomic"
 	"testing"
 )
 
@@ -77,3 +79,75 @@ func TestPinkSimplexOctave(t *testing.T) {
 	}
 	testWithNoiser(t, octaveGenerator, "octave_simplex_test.png")
 }
+
+// cancellingNoise cancels its context on the first sample and counts the
+// samples taken.
+type cancellingNoise struct {
+	cancel  context.CancelFunc
+	samples int64
+}
+
+func (c *cancellingNoise) Noise(x, y float64) float64 {
+	atomic.AddInt64(&c.samples, 1)
+	c.cancel()
+	return x
+}
+
+func TestWriteGreyImagePngWorkers(t *testing.T) {
+	var want bytes.Buffer
+	if err := WriteGreyImagePng(&want, NewPerlin(seed), startCorner, startCorner, 50, 40, sampleStep); err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{-1, 1, 3, 100} {
+		var got bytes.Buffer
+		if err := WriteGreyImagePngContext(context.Background(), &got, NewPerlin(seed), startCorner, startCorner, 50, 40, sampleStep, workers, Version1); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("%d workers wrote a different image", workers)
+		}
+	}
+}
+
+func TestWriteGreyImagePngContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	noiser := &cancellingNoise{cancel: cancel}
+	var buffer bytes.Buffer
+	err := WriteGreyImagePngContext(ctx, &buffer, noiser, 0, 0, 10, 1000, 1, 2, LatestVersion)
+	if err != context.Canceled {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if buffer.Len() != 0 {
+		t.Error("wrote an image after cancellation")
+	}
+	// Each of the two workers may be part way through its first sample.
+	if samples := atomic.LoadInt64(&noiser.samples); samples > 2 {
+		t.Errorf("took %d samples after cancellation", samples)
+	}
+}
+
+// finishingNoise cancels its context on the last of its samples.
+type finishingNoise struct {
+	cancel    context.CancelFunc
+	remaining int64
+}
+
+func (f *finishingNoise) Noise(x, y float64) float64 {
+	if atomic.AddInt64(&f.remaining, -1) == 0 {
+		f.cancel()
+	}
+	return x
+}
+
+func TestWriteGreyImagePngContextCancelAfterLastSample(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	noiser := &finishingNoise{cancel: cancel, remaining: 10 * 20}
+	var buffer bytes.Buffer
+	if err := WriteGreyImagePngContext(ctx, &buffer, noiser, 0, 0, 10, 20, 1, 3, LatestVersion); err != nil {
+		t.Fatalf("got error %v after every sample was taken", err)
+	}
+	if buffer.Len() == 0 {
+		t.Error("wrote no image")
+	}
+}
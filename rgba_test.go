@@ -0,0 +1,89 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestNewRGBAImage(t *testing.T) {
+	channels := []Channel{
+		{Noiser: rowNoise{}, Normalizer: &FixedRange{Min: 1, Max: 3}},
+		{},
+		{Noiser: constantNoise(0.25), Normalizer: &FixedRange{Min: 0, Max: 1}},
+	}
+	img, err := NewRGBAImage(context.Background(), channels, 0, 1, 2, 3, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, red := range []uint16{0, 32767, 65535} {
+		want := color.NRGBA64{R: red, G: 0, B: 16383, A: 65535}
+		if got := img.NRGBA64At(0, 2-i); got != want {
+			t.Errorf("row %d color = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestNewRGBAImageAlpha(t *testing.T) {
+	channels := []Channel{
+		{},
+		{},
+		{},
+		{Noiser: rowNoise{}},
+	}
+	img, err := NewRGBAImage(context.Background(), channels, 0, 1, 1, 3, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, alpha := range []uint16{0, 32767, 65535} {
+		if got := img.NRGBA64At(0, 2-i).A; got != alpha {
+			t.Errorf("row %d alpha = %d, want %d", i, got, alpha)
+		}
+	}
+}
+
+func TestWriteRGBAImagePng(t *testing.T) {
+	channels := []Channel{{Noiser: NewPerlin(seed)}, {Noiser: NewSimplex(seed)}}
+	var buffer bytes.Buffer
+	if err := WriteRGBAImagePng(context.Background(), &buffer, channels, startCorner, startCorner, 16, 8, sampleStep, 0); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := NewRGBAImage(context.Background(), channels, startCorner, startCorner, 16, 8, sampleStep, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			if got := color.NRGBA64Model.Convert(img.At(x, y)); got != want.NRGBA64At(x, y) {
+				t.Fatalf("pixel %d, %d = %v, want %v", x, y, got, want.NRGBA64At(x, y))
+			}
+		}
+	}
+
+	if err := WriteRGBAImagePng(context.Background(), &buffer, make([]Channel, 5), 0, 0, 1, 1, 1, 0); err == nil {
+		t.Error("no error for five channels")
+	}
+}
@@ -0,0 +1,70 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// Seed is the seed of a generator. Seeds can be written as numbers or derived
+// from text that players type, and are passed to any constructor taking an
+// int64 seed using Int64.
+//
+// Generators built on math/rand tables, such as those from NewPerlin, only use
+// the seed modulo 2^31-1. Stable and hashed generators use all 64 bits.
+type Seed int64
+
+// SeedFromString hashes the text into a seed. The seed is the 64-bit FNV-1a
+// hash of the UTF-8 bytes of the text, so the same text gives the same seed on
+// every platform. The text is not normalized: letter case, whitespace and
+// Unicode composition all change the seed.
+func SeedFromString(text string) Seed {
+	return SeedFromBytes([]byte(text))
+}
+
+// SeedFromBytes hashes the bytes into a seed using the 64-bit FNV-1a hash.
+func SeedFromBytes(b []byte) Seed {
+	h := fnv.New64a()
+	h.Write(b)
+	return Seed(h.Sum64())
+}
+
+// Int64 returns the seed for use with the generator constructors.
+func (s Seed) Int64() int64 {
+	return int64(s)
+}
+
+// Derive returns an independent child seed for the labelled part of a noise
+// graph, such as Derive("octave", 2) for the third octave or
+// Derive("moisture", 0) for a layer. The same seed, label and index always
+// give the same child, and changing any of them gives an unrelated one.
+//
+// The child is the first value of a SplitMix64 stream whose initial state is
+// the 64-bit FNV-1a hash of the seed as 8 little-endian bytes, the UTF-8
+// bytes of the label, and the index as 8 little-endian bytes.
+func (s Seed) Derive(label string, index int) Seed {
+	var buf [8]byte
+	h := fnv.New64a()
+	binary.LittleEndian.PutUint64(buf[:], uint64(s))
+	h.Write(buf[:])
+	h.Write([]byte(label))
+	binary.LittleEndian.PutUint64(buf[:], uint64(index))
+	h.Write(buf[:])
+	return Seed(newSplitMix64(int64(h.Sum64())).Uint64())
+}
@@ -0,0 +1,110 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSeedFromStringIsStable(t *testing.T) {
+	tests := []struct {
+		text string
+		want uint64
+	}{
+		{"", 14695981039346656037},
+		{"a", 12638187200555641996},
+		{"Glacier Bay", 1091358928584444698},
+	}
+	for _, test := range tests {
+		if got := uint64(SeedFromString(test.text)); got != test.want {
+			t.Errorf("SeedFromString(%q) = %d, want %d", test.text, got, test.want)
+		}
+		if got := uint64(SeedFromBytes([]byte(test.text))); got != test.want {
+			t.Errorf("SeedFromBytes(%q) = %d, want %d", test.text, got, test.want)
+		}
+	}
+}
+
+func TestStringSeededNoise(t *testing.T) {
+	s := SeedFromString("Glacier Bay")
+	assertGolden(t, "stable Perlin", NewPerlinFromTable(NewStablePermutationTable(s.Int64(), DefaultTableSize), nil, nil), []float64{
+		-0.21650635094610965, -0.08840675355226113, -0.13889666560687614, 0.18832796163844603, -0.0003660353714967211,
+	})
+	assertGolden(t, "hashed Perlin", NewPerlinHashed(s.Int64()), []float64{
+		-0.2957531754730549, 0.2634850521249941, -0.03672053266357165, -0.015259052602738687, 0.0003660353814816655,
+	})
+	if SeedFromString("glacier bay") == s {
+		t.Error("seeds differing in case are equal")
+	}
+}
+
+func TestSeedDeriveIsStable(t *testing.T) {
+	s := Seed(42)
+	tests := []struct {
+		label string
+		index int
+		want  int64
+	}{
+		{"octave", 0, 1680733761862905678},
+		{"octave", 1, -2901872935199535845},
+		{"moisture", 0, 1435365351135012344},
+	}
+	for _, test := range tests {
+		if got := s.Derive(test.label, test.index).Int64(); got != test.want {
+			t.Errorf("Derive(%q, %d) = %d, want %d", test.label, test.index, got, test.want)
+		}
+	}
+}
+
+func TestSeedDeriveIsDistinct(t *testing.T) {
+	seen := make(map[Seed]string)
+	for _, s := range []Seed{0, 1, 42} {
+		for _, label := range []string{"octave", "layer", ""} {
+			for i := 0; i < 64; i++ {
+				child := s.Derive(label, i)
+				name := fmt.Sprintf("Seed(%d).Derive(%q, %d)", s, label, i)
+				if other, ok := seen[child]; ok {
+					t.Fatalf("%s and %s are both %d", name, other, child)
+				}
+				seen[child] = name
+			}
+		}
+	}
+}
+
+func TestSeededOctaveNoise(t *testing.T) {
+	s := Seed(42)
+	newStablePerlin := func(seed int64) Noiser {
+		return NewPerlinFromTable(NewStablePermutationTable(seed, DefaultTableSize), nil, nil)
+	}
+	o := NewSeededOctaveNoise(0.5, 4, s, newStablePerlin)
+	assertGolden(t, "OctaveNoise", o, []float64{
+		-0.6372595264191645, 0.15902383786907864, -0.1496604934239636, 0.0031241053647939054, 0.0024636347183654176,
+	})
+
+	want := NewOctaveNoise(0.5)
+	for i := 0; i < 4; i++ {
+		want.AddOctave(newStablePerlin(s.Derive("octave", i).Int64()))
+	}
+	for _, p := range goldenPoints {
+		if got, w := o.Noise(p[0], p[1]), want.Noise(p[0], p[1]); got != w {
+			t.Errorf("Noise(%v, %v) = %v, want %v", p[0], p[1], got, w)
+		}
+	}
+}
@@ -17,51 +17,218 @@
 
 package noise
 
-import (
-	"math/rand"
-)
-
-var _ Noiser = &Simplex{}
+var _ BatchNoiser = &Simplex{}
+var _ GridNoiser = &Simplex{}
+var _ GradientNoiser = &Simplex{}
 
 // Simplex implements simplex noise generation in two dimensions.
 type Simplex struct {
-	rng  *rand.Rand
-	hash []int
+	lattice *lattice
 }
 
 // NewSimplex returns a new source of simplex noise. Identical seeds generate
 // identical noise for the same inputs.
 func NewSimplex(seed int64) *Simplex {
-	s := &Simplex{
-		rng:  rand.New(rand.NewSource(seed)),
-		hash: make([]int, 0, hashSize2D*2),
+	return NewSimplexWithGradients(seed, DefaultTableSize, nil)
+}
+
+// NewSimplexWithGradients returns a new source of simplex noise whose lattice
+// assigns gradients from the given set using a hash table of the given size.
+// A non-positive table size uses DefaultTableSize and no gradients uses
+// DefaultGradients.
+func NewSimplexWithGradients(seed int64, tableSize int, gradients []Gradient) *Simplex {
+	return NewSimplexFromTable(NewPermutationTable(seed, tableSize), gradients)
+}
+
+// NewSimplexFromTable returns a new source of simplex noise that shares the
+// permutation table, so that many generators need only one table. No
+// gradients uses DefaultGradients.
+func NewSimplexFromTable(table *PermutationTable, gradients []Gradient) *Simplex {
+	return &Simplex{
+		lattice: newLatticeFromTable(table, gradients),
 	}
-	s.init()
-	return s
 }
 
-// init creates the gradient hash lookup used for noise generation.
-func (s *Simplex) init() {
-	for i := 0; i < hashSize2D; i++ {
-		s.hash = append(s.hash, s.rng.Intn(hashSize2D))
+// NewSimplexVersion constructs a new Simplex noise with the given seed whose
+// values are frozen at the algorithm version. Version1 gives the same noise as
+// NewSimplex.
+func NewSimplexVersion(seed int64, version Version) (*Simplex, error) {
+	table, err := version.permutationTable(seed)
+	if err != nil {
+		return nil, err
+	}
+	return NewSimplexFromTable(table, nil), nil
+}
+
+// NewSimplexHashed returns a new source of simplex noise that assigns
+// gradients by hashing each lattice point with the seed instead of using a
+// permutation table. It needs no table, never repeats, and every 64-bit seed
+// gives different noise. It gives different values than NewSimplex for the
+// same seed.
+func NewSimplexHashed(seed int64) *Simplex {
+	return &Simplex{
+		lattice: newHashedLattice(seed),
 	}
-	s.hash = append(s.hash, s.hash...)
 }
 
 // Noise creates two-dimensional simplex noise.
 func (s *Simplex) Noise(x, y float64) float64 {
-	commonFactorUnskew := (x + y) * coordTransformToUnskew(2)
-	simplexX := intFloor(x + commonFactorUnskew)
-	simplexY := intFloor(y + commonFactorUnskew)
+	simplexX, simplexY, firstX, firstY := simplexCell(x, y)
+
+	unitX := 0
+	unitY := 0
+	if firstX > firstY {
+		unitX = 1 // Lower Simplex
+	} else {
+		unitY = 1 // Upper Simplex
+	}
+
+	simplexX = s.lattice.wrap(simplexX)
+	simplexY = s.lattice.wrap(simplexY)
+
+	grad0 := s.lattice.gradient(simplexX, simplexY)
+	grad1 := s.lattice.gradient(simplexX+unitX, simplexY+unitY)
+	grad2 := s.lattice.gradient(simplexX+1, simplexY+1)
+	return simplexContributions(grad0, grad1, grad2, firstX, firstY)
+}
+
+// NoiseGradient computes the exact gradient of the simplex noise.
+func (s *Simplex) NoiseGradient(x, y float64) (dx, dy float64) {
+	simplexX, simplexY, firstX, firstY := simplexCell(x, y)
+
+	unitX := 0
+	unitY := 0
+	if firstX > firstY {
+		unitX = 1 // Lower Simplex
+	} else {
+		unitY = 1 // Upper Simplex
+	}
+
+	simplexX = s.lattice.wrap(simplexX)
+	simplexY = s.lattice.wrap(simplexY)
 
 	skewFactor := coordTransformToSkew(2)
-	commonFactorSkew := float64(simplexX+simplexY) * skewFactor
+	corners := [3]struct {
+		grad   point2D
+		offset point2D
+	}{
+		{s.lattice.gradient(simplexX, simplexY), point2D{firstX, firstY}},
+		{s.lattice.gradient(simplexX+unitX, simplexY+unitY), point2D{firstX - float64(unitX) - skewFactor, firstY - float64(unitY) - skewFactor}},
+		{s.lattice.gradient(simplexX+1, simplexY+1), point2D{firstX - 1 - float64(2*skewFactor), firstY - 1 - float64(2*skewFactor)}},
+	}
+	// Each corner contributes t^4 (g.d) where t = 0.5 - d.d, whose gradient
+	// is t^4 g - 8 t^3 (g.d) d.
+	for _, c := range corners {
+		t := 0.5 - c.offset.Dot(c.offset)
+		if t <= 0 {
+			continue
+		}
+		t3 := t * t * t
+		dot := c.grad.Dot(c.offset)
+		dx += float64(t3*t*c.grad.X) - float64(8*t3*dot*c.offset.X)
+		dy += float64(t3*t*c.grad.Y) - float64(8*t3*dot*c.offset.Y)
+	}
+	return
+}
+
+// NoiseBatch creates two-dimensional simplex noise for many points. The
+// gradients of a skewed cell are reused for consecutive points that lie in
+// the same cell.
+func (s *Simplex) NoiseBatch(xs, ys, out []float64) {
+	xs = xs[:len(out)]
+	ys = ys[:len(out)]
+	var grad0, gradLower, gradUpper, grad2 point2D
+	cellX, cellY := 0, 0
+	for i := range out {
+		simplexX, simplexY, firstX, firstY := simplexCell(xs[i], ys[i])
+		if i == 0 || simplexX != cellX || simplexY != cellY {
+			cellX, cellY = simplexX, simplexY
+			simplexX = s.lattice.wrap(simplexX)
+			simplexY = s.lattice.wrap(simplexY)
+			grad0 = s.lattice.gradient(simplexX, simplexY)
+			gradLower = s.lattice.gradient(simplexX+1, simplexY)
+			gradUpper = s.lattice.gradient(simplexX, simplexY+1)
+			grad2 = s.lattice.gradient(simplexX+1, simplexY+1)
+		}
+		if firstX > firstY {
+			out[i] = simplexContributions(grad0, gradLower, grad2, firstX, firstY)
+		} else {
+			out[i] = simplexContributions(grad0, gradUpper, grad2, firstX, firstY)
+		}
+	}
+}
+
+// FillGrid samples simplex noise on a regular grid as described by
+// GridNoiser. The skewed coordinates are stepped incrementally along each row,
+// and gradients are reused while a row stays in one skewed cell.
+func (s *Simplex) FillGrid(dst []float64, stride int, minX, minY, dx, dy float64) {
+	if stride <= 0 {
+		return
+	}
+	skewFactor := coordTransformToSkew(2)
+	unskewFactor := coordTransformToUnskew(2)
+	skewDX := dx + float64(dx*unskewFactor)
+	skewDY := float64(dx * unskewFactor)
+
+	for row := 0; row < len(dst)/stride; row++ {
+		noiseY := minY + float64(float64(row)*dy)
+		commonFactorUnskew := float64((minX + noiseY) * unskewFactor)
+		skewX := minX + commonFactorUnskew
+		skewY := noiseY + commonFactorUnskew
+		cellX := intFloor(skewX)
+		cellY := intFloor(skewY)
+		relX := skewX - float64(cellX)
+		relY := skewY - float64(cellY)
+
+		var grad0, gradLower, gradUpper, grad2 point2D
+		lastX, lastY := 0, 0
+		line := dst[row*stride : (row+1)*stride]
+		for col := range line {
+			if col > 0 {
+				cellX, relX = latticeStep(cellX, relX, skewDX)
+				cellY, relY = latticeStep(cellY, relY, skewDY)
+			}
+			if col == 0 || cellX != lastX || cellY != lastY {
+				lastX, lastY = cellX, cellY
+				simplexX := s.lattice.wrap(cellX)
+				simplexY := s.lattice.wrap(cellY)
+				grad0 = s.lattice.gradient(simplexX, simplexY)
+				gradLower = s.lattice.gradient(simplexX+1, simplexY)
+				gradUpper = s.lattice.gradient(simplexX, simplexY+1)
+				grad2 = s.lattice.gradient(simplexX+1, simplexY+1)
+			}
+			commonFactorSkew := float64((relX + relY) * skewFactor)
+			firstX := relX + commonFactorSkew
+			firstY := relY + commonFactorSkew
+			if firstX > firstY {
+				line[col] = simplexContributions(grad0, gradLower, grad2, firstX, firstY)
+			} else {
+				line[col] = simplexContributions(grad0, gradUpper, grad2, firstX, firstY)
+			}
+		}
+	}
+}
+
+// simplexCell skews a point to find the origin of the simplex cell containing
+// it, along with the point's unskewed offset from that origin.
+func simplexCell(x, y float64) (simplexX, simplexY int, firstX, firstY float64) {
+	commonFactorUnskew := float64((x + y) * coordTransformToUnskew(2))
+	simplexX = intFloor(x + commonFactorUnskew)
+	simplexY = intFloor(y + commonFactorUnskew)
+
+	skewFactor := coordTransformToSkew(2)
+	commonFactorSkew := float64(float64(simplexX+simplexY) * skewFactor)
 	skewSimplexX := float64(simplexX) + commonFactorSkew
 	skewSimplexY := float64(simplexY) + commonFactorSkew
 
-	firstX := x - skewSimplexX
-	firstY := y - skewSimplexY
+	firstX = x - skewSimplexX
+	firstY = y - skewSimplexY
+	return
+}
 
+// simplexContributions sums the contributions of the three simplex corners
+// given their gradients and the point's offset from the first corner.
+func simplexContributions(grad0, grad1, grad2 point2D, firstX, firstY float64) float64 {
 	unitX := 0
 	unitY := 0
 	if firstX > firstY {
@@ -70,40 +237,28 @@ func (s *Simplex) Noise(x, y float64) float64 {
 		unitY = 1 // Upper Simplex
 	}
 
+	skewFactor := coordTransformToSkew(2)
 	middleX := firstX - float64(unitX) - skewFactor
 	middleY := firstY - float64(unitY) - skewFactor
-	lastX := firstX - 1 - 2*skewFactor
-	lastY := firstY - 1 - 2*skewFactor
-
-	simplexX = intMod(simplexX, hashSize2D)
-	for simplexX < 0 {
-		simplexX += hashSize2D
-	}
-	simplexY = intMod(simplexY, hashSize2D)
-	for simplexY < 0 {
-		simplexY += hashSize2D
-	}
-
-	grad0 := intMod(s.hash[simplexX+s.hash[simplexY]], len(gradient2D))
-	grad1 := intMod(s.hash[simplexX+unitX+s.hash[simplexY+unitY]], len(gradient2D))
-	grad2 := intMod(s.hash[simplexX+1+s.hash[simplexY+1]], len(gradient2D))
+	lastX := firstX - 1 - float64(2*skewFactor)
+	lastY := firstY - 1 - float64(2*skewFactor)
 
-	t0 := 0.5 - firstX*firstX - firstY*firstY
-	t1 := 0.5 - middleX*middleX - middleY*middleY
-	t2 := 0.5 - lastX*lastX - lastY*lastY
+	t0 := 0.5 - float64(firstX*firstX) - float64(firstY*firstY)
+	t1 := 0.5 - float64(middleX*middleX) - float64(middleY*middleY)
+	t2 := 0.5 - float64(lastX*lastX) - float64(lastY*lastY)
 
 	contrib0 := 0.0
 	contrib1 := 0.0
 	contrib2 := 0.0
 
 	if t0 > 0 {
-		contrib0 = t0 * t0 * t0 * t0 * gradient2D[grad0].DotFloat64(firstX, firstY)
+		contrib0 = float64(t0 * t0 * t0 * t0 * grad0.DotFloat64(firstX, firstY))
 	}
 	if t1 > 0 {
-		contrib1 = t1 * t1 * t1 * t1 * gradient2D[grad1].DotFloat64(middleX, middleY)
+		contrib1 = float64(t1 * t1 * t1 * t1 * grad1.DotFloat64(middleX, middleY))
 	}
 	if t2 > 0 {
-		contrib2 = t2 * t2 * t2 * t2 * gradient2D[grad2].DotFloat64(lastX, lastY)
+		contrib2 = float64(t2 * t2 * t2 * t2 * grad2.DotFloat64(lastX, lastY))
 	}
 	return contrib0 + contrib1 + contrib2
 }
@@ -0,0 +1,103 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+var _ Noiser = &Simplex32{}
+var _ Noiser32 = &Simplex32{}
+
+// skewFactor32 and unskewFactor32 are the two dimensional simplex skewing
+// factors in single precision.
+var (
+	skewFactor32   = float32(coordTransformToSkew(2))
+	unskewFactor32 = float32(coordTransformToUnskew(2))
+)
+
+// Simplex32 implements the same noise as Simplex using single precision
+// arithmetic. For the same seed and float32 inputs, its values are within
+// Float32Tolerance of the values of Simplex.
+type Simplex32 struct {
+	lattice *lattice
+}
+
+// NewSimplex32 returns a new source of single precision simplex noise. The
+// seed produces the same lattice as NewSimplex.
+func NewSimplex32(seed int64) *Simplex32 {
+	return NewSimplex32FromTable(NewPermutationTable(seed, DefaultTableSize))
+}
+
+// NewSimplex32FromTable returns a new source of single precision simplex noise
+// that shares the permutation table, so that many generators need only one
+// table.
+func NewSimplex32FromTable(table *PermutationTable) *Simplex32 {
+	return &Simplex32{
+		lattice: newLatticeFromTable(table, nil),
+	}
+}
+
+// Noise creates two-dimensional simplex noise, converting to and from single
+// precision.
+func (s *Simplex32) Noise(x, y float64) float64 {
+	return float64(s.Noise32(float32(x), float32(y)))
+}
+
+// Noise32 creates two-dimensional simplex noise in single precision.
+func (s *Simplex32) Noise32(x, y float32) float32 {
+	commonFactorUnskew := (x + y) * unskewFactor32
+	simplexX := intFloor32(x + commonFactorUnskew)
+	simplexY := intFloor32(y + commonFactorUnskew)
+
+	commonFactorSkew := float32(simplexX+simplexY) * skewFactor32
+	firstX := x - (float32(simplexX) + commonFactorSkew)
+	firstY := y - (float32(simplexY) + commonFactorSkew)
+
+	unitX := 0
+	unitY := 0
+	if firstX > firstY {
+		unitX = 1 // Lower Simplex
+	} else {
+		unitY = 1 // Upper Simplex
+	}
+
+	middleX := firstX - float32(unitX) - skewFactor32
+	middleY := firstY - float32(unitY) - skewFactor32
+	lastX := firstX - 1 - 2*skewFactor32
+	lastY := firstY - 1 - 2*skewFactor32
+
+	simplexX = s.lattice.wrap(simplexX)
+	simplexY = s.lattice.wrap(simplexY)
+
+	grad0 := gradient2D32[intMod(s.lattice.hash[simplexX+s.lattice.hash[simplexY]], len(gradient2D32))]
+	grad1 := gradient2D32[intMod(s.lattice.hash[simplexX+unitX+s.lattice.hash[simplexY+unitY]], len(gradient2D32))]
+	grad2 := gradient2D32[intMod(s.lattice.hash[simplexX+1+s.lattice.hash[simplexY+1]], len(gradient2D32))]
+
+	t0 := 0.5 - firstX*firstX - firstY*firstY
+	t1 := 0.5 - middleX*middleX - middleY*middleY
+	t2 := 0.5 - lastX*lastX - lastY*lastY
+
+	var contrib0, contrib1, contrib2 float32
+	if t0 > 0 {
+		contrib0 = t0 * t0 * t0 * t0 * grad0.DotFloat32(firstX, firstY)
+	}
+	if t1 > 0 {
+		contrib1 = t1 * t1 * t1 * t1 * grad1.DotFloat32(middleX, middleY)
+	}
+	if t2 > 0 {
+		contrib2 = t2 * t2 * t2 * t2 * grad2.DotFloat32(lastX, lastY)
+	}
+	return contrib0 + contrib1 + contrib2
+}
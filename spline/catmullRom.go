@@ -0,0 +1,285 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package spline
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Alpha values for the common kinds of Catmull-Rom splines.
+const (
+	Uniform     = 0.0
+	Centripetal = 0.5
+	Chordal     = 1.0
+)
+
+// samplesPerSegment is the number of pieces each segment of a spline is
+// divided into when estimating its length and searching for x-values.
+const samplesPerSegment = 64
+
+// bisectionSteps is the number of halvings used to refine the parametric value
+// of an x-value lookup.
+const bisectionSteps = 52
+
+// Point is a two-dimensional control point or point on a spline.
+type Point struct {
+	X, Y float64
+}
+
+// Add adds a point to this one.
+func (p Point) Add(o Point) Point {
+	return Point{p.X + o.X, p.Y + o.Y}
+}
+
+// Scale applies a scalar to both x and y values of this point.
+func (p Point) Scale(s float64) Point {
+	return Point{p.X * s, p.Y * s}
+}
+
+// Distance determines the distance between two points.
+func (p Point) Distance(o Point) float64 {
+	return math.Hypot(o.X-p.X, o.Y-p.Y)
+}
+
+// sample is a point along a spline, with its parametric value and its
+// estimated distance from the start of the spline.
+type sample struct {
+	t, length float64
+	p         Point
+}
+
+// CatmullRom is a Catmull-Rom spline through a list of control points. It is
+// safe for concurrent use.
+type CatmullRom struct {
+	alpha   float64
+	points  []Point
+	knots   []float64
+	samples []sample
+}
+
+// New creates a Catmull-Rom spline through the control points. If the alpha
+// value is 0.5, then the spline is a centripetal one. If alpha is 1.0, then
+// the spline is a chordal one. Finally, if alpha is 0, the spline is a uniform
+// one. At least four points are required, and unless the spline is uniform,
+// consecutive points must differ.
+func New(alpha float64, points []Point) (*CatmullRom, error) {
+	if len(points) < 4 {
+		return nil, errors.New("at least four points are required")
+	}
+	c := &CatmullRom{
+		alpha:  alpha,
+		points: append([]Point(nil), points...),
+		knots:  make([]float64, len(points)),
+	}
+	for i := 1; i < len(points); i++ {
+		step := math.Pow(points[i-1].Distance(points[i]), alpha)
+		if step == 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+			return nil, errors.New("consecutive points must differ")
+		}
+		c.knots[i] = c.knots[i-1] + step
+	}
+	c.initSamples()
+	return c, nil
+}
+
+// NewUniform creates a uniform Catmull-Rom spline.
+func NewUniform(points []Point) (*CatmullRom, error) {
+	return New(Uniform, points)
+}
+
+// NewCentripetal creates a centripetal Catmull-Rom spline.
+func NewCentripetal(points []Point) (*CatmullRom, error) {
+	return New(Centripetal, points)
+}
+
+// NewChordal creates a chordal Catmull-Rom spline.
+func NewChordal(points []Point) (*CatmullRom, error) {
+	return New(Chordal, points)
+}
+
+// initSamples divides the spline into short pieces used for estimating its
+// length and searching for x-values.
+func (c *CatmullRom) initSamples() {
+	segments := len(c.points) - 3
+	c.samples = make([]sample, 0, segments*samplesPerSegment+1)
+	for seg := 0; seg < segments; seg++ {
+		lower, upper := c.knots[seg+1], c.knots[seg+2]
+		start := 1
+		if seg == 0 {
+			start = 0
+		}
+		for i := start; i <= samplesPerSegment; i++ {
+			t := lower + (upper-lower)*float64(i)/samplesPerSegment
+			s := sample{t: t, p: c.segmentAt(seg, t)}
+			if n := len(c.samples); n > 0 {
+				s.length = c.samples[n-1].length + c.samples[n-1].p.Distance(s.p)
+			}
+			c.samples = append(c.samples, s)
+		}
+	}
+}
+
+// LowerT returns the lower parametric value for the spline curve, where it
+// passes through the second control point.
+func (c *CatmullRom) LowerT() float64 {
+	return c.knots[1]
+}
+
+// UpperT returns the upper parametric value for the spline curve, where it
+// passes through the second to last control point.
+func (c *CatmullRom) UpperT() float64 {
+	return c.knots[len(c.knots)-2]
+}
+
+// Knot returns the parametric value at which the spline passes through the
+// i-th control point. The first and last control points are not on the curve.
+func (c *CatmullRom) Knot(i int) float64 {
+	return c.knots[i]
+}
+
+// At returns the spline's point at the parametric value. Values of t outside
+// of LowerT and UpperT are clamped to that range.
+func (c *CatmullRom) At(t float64) Point {
+	t = math.Max(c.LowerT(), math.Min(c.UpperT(), t))
+	seg := sort.Search(len(c.points)-3, func(i int) bool {
+		return c.knots[i+2] >= t
+	})
+	return c.segmentAt(seg, t)
+}
+
+// segmentAt evaluates the segment between control points seg+1 and seg+2
+// using the Barry and Goldman pyramidal formulation.
+func (c *CatmullRom) segmentAt(seg int, t float64) Point {
+	p0, p1, p2, p3 := c.points[seg], c.points[seg+1], c.points[seg+2], c.points[seg+3]
+	t0, t1, t2, t3 := c.knots[seg], c.knots[seg+1], c.knots[seg+2], c.knots[seg+3]
+
+	a1 := blend(p0, p1, t0, t1, t)
+	a2 := blend(p1, p2, t1, t2, t)
+	a3 := blend(p2, p3, t2, t3, t)
+
+	b1 := blend(a1, a2, t0, t2, t)
+	b2 := blend(a2, a3, t1, t3, t)
+
+	return blend(b1, b2, t1, t2, t)
+}
+
+// blend linearly interpolates between two points whose parametric values are
+// tLower and tUpper.
+func blend(lower, upper Point, tLower, tUpper, t float64) Point {
+	return lower.Scale((tUpper - t) / (tUpper - tLower)).Add(upper.Scale((t - tLower) / (tUpper - tLower)))
+}
+
+// Length returns the estimated length of the spline curve.
+func (c *CatmullRom) Length() float64 {
+	return c.samples[len(c.samples)-1].length
+}
+
+// ParameterAtLength returns the parametric value at the given distance along
+// the curve from its start. Distances outside zero and Length are clamped to
+// that range.
+func (c *CatmullRom) ParameterAtLength(s float64) float64 {
+	if s <= 0 {
+		return c.LowerT()
+	} else if s >= c.Length() {
+		return c.UpperT()
+	}
+	i := sort.Search(len(c.samples), func(i int) bool {
+		return c.samples[i].length >= s
+	})
+	lower, upper := c.samples[i-1], c.samples[i]
+	frac := (s - lower.length) / (upper.length - lower.length)
+	return lower.t + (upper.t-lower.t)*frac
+}
+
+// AtLength returns the spline's point at the given distance along the curve
+// from its start. Evenly spaced distances give points that are evenly spaced
+// along the curve, unlike evenly spaced parametric values.
+func (c *CatmullRom) AtLength(s float64) Point {
+	return c.At(c.ParameterAtLength(s))
+}
+
+// InterpolateX returns the y-value of the first point along the curve whose
+// x-value is x. It returns false if the curve does not reach x.
+func (c *CatmullRom) InterpolateX(x float64) (float64, bool) {
+	var y float64
+	found := false
+	c.crossings(x, func(p Point) bool {
+		y = p.Y
+		found = true
+		return false
+	})
+	return y, found
+}
+
+// InterpolateXAll returns the y-values of every point along the curve whose
+// x-value is x, in the order they occur along the curve. Curves that loop back
+// on themselves may reach the same x-value several times. Crossings closer
+// together than the sampling resolution of the curve may be missed.
+func (c *CatmullRom) InterpolateXAll(x float64) []float64 {
+	var ys []float64
+	c.crossings(x, func(p Point) bool {
+		ys = append(ys, p.Y)
+		return true
+	})
+	return ys
+}
+
+// crossings calls found for each point along the curve whose x-value is x,
+// stopping early if found returns false.
+func (c *CatmullRom) crossings(x float64, found func(Point) bool) {
+	for i := 0; i+1 < len(c.samples); i++ {
+		lower, upper := c.samples[i], c.samples[i+1]
+		if lower.p.X == x {
+			if !found(lower.p) {
+				return
+			}
+			continue
+		}
+		if (lower.p.X-x)*(upper.p.X-x) >= 0 {
+			continue
+		}
+		if !found(c.bisectX(lower, upper, x)) {
+			return
+		}
+	}
+	if last := c.samples[len(c.samples)-1]; last.p.X == x {
+		found(last.p)
+	}
+}
+
+// bisectX refines the point between two samples whose x-values lie on either
+// side of x.
+func (c *CatmullRom) bisectX(lower, upper sample, x float64) Point {
+	tLower, tUpper := lower.t, upper.t
+	below := lower.p.X < x
+	p := lower.p
+	for i := 0; i < bisectionSteps; i++ {
+		t := (tLower + tUpper) / 2
+		p = c.At(t)
+		if p.X == x {
+			break
+		} else if (p.X < x) == below {
+			tLower = t
+		} else {
+			tUpper = t
+		}
+	}
+	return p
+}
@@ -0,0 +1,111 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package spline
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+var wave = []Point{{-1, 0}, {0, 1}, {1, -1}, {2, 2}, {3, 0}, {4, 1}}
+
+func TestPassesThroughControlPoints(t *testing.T) {
+	for _, alpha := range []float64{Uniform, Centripetal, Chordal} {
+		c, err := New(alpha, wave)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i < len(wave)-1; i++ {
+			p := c.At(c.Knot(i))
+			if p.Distance(wave[i]) > tolerance {
+				t.Errorf("alpha %v: At(Knot(%d)) = %v, want %v", alpha, i, p, wave[i])
+			}
+		}
+	}
+}
+
+func TestRequiresValidPoints(t *testing.T) {
+	if _, err := NewCentripetal(wave[:3]); err == nil {
+		t.Error("expected an error for three points")
+	}
+	repeated := []Point{{0, 0}, {1, 1}, {1, 1}, {2, 0}}
+	if _, err := NewChordal(repeated); err == nil {
+		t.Error("expected an error for repeated points")
+	}
+	if _, err := NewUniform(repeated); err != nil {
+		t.Errorf("uniform splines allow repeated points: %s", err)
+	}
+}
+
+func TestArcLength(t *testing.T) {
+	line := []Point{{0, 0}, {1, 1}, {2, 2}, {4, 4}, {5, 5}}
+	c, err := NewUniform(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3 * math.Sqrt2; math.Abs(c.Length()-want) > 1e-6 {
+		t.Errorf("Length() = %v, want %v", c.Length(), want)
+	}
+	for i := 0; i <= 10; i++ {
+		s := c.Length() * float64(i) / 10
+		p := c.AtLength(s)
+		if got := p.Distance(line[1]); math.Abs(got-s) > 1e-3 {
+			t.Errorf("AtLength(%v) is %v from the start", s, got)
+		}
+	}
+}
+
+func TestInterpolateXMonotone(t *testing.T) {
+	c, err := NewCentripetal(wave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(wave)-1; i++ {
+		y, ok := c.InterpolateX(wave[i].X)
+		if !ok || math.Abs(y-wave[i].Y) > tolerance {
+			t.Errorf("InterpolateX(%v) = %v, %v, want %v", wave[i].X, y, ok, wave[i].Y)
+		}
+	}
+	if _, ok := c.InterpolateX(10); ok {
+		t.Error("InterpolateX found a value beyond the curve")
+	}
+}
+
+func TestInterpolateXNonMonotone(t *testing.T) {
+	// An S shaped curve that doubles back, reaching x = 1 three times.
+	s := []Point{{-1, -1}, {0, 0}, {2, 1}, {0, 2}, {2, 3}, {3, 4}}
+	c, err := NewCentripetal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ys := c.InterpolateXAll(1)
+	if len(ys) != 3 {
+		t.Fatalf("InterpolateXAll(1) = %v, want three values", ys)
+	}
+	for i, y := range ys {
+		if i > 0 && y <= ys[i-1] {
+			t.Errorf("InterpolateXAll(1) = %v, want values in curve order", ys)
+		}
+	}
+	first, ok := c.InterpolateX(1)
+	if !ok || first != ys[0] {
+		t.Errorf("InterpolateX(1) = %v, %v, want %v", first, ok, ys[0])
+	}
+}
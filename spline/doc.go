@@ -0,0 +1,37 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Package spline provides Catmull-Rom splines through arbitrary lists of
+	two-dimensional control points. Uniform, centripetal and chordal
+	parameterizations are supported through the alpha value of the spline.
+
+	A spline passes through every control point except the first and the
+	last, which only shape the ends of the curve. It can be evaluated by its
+	parametric value, by distance along the curve, or by looking up the
+	y-values where the curve reaches a given x-value.
+
+		// A centripetal camera path.
+		path, err := spline.NewCentripetal([]spline.Point{
+			{0, 0}, {1, 2}, {3, 3}, {4, 1}, {6, 0},
+		})
+		// Halfway along the path by distance.
+		p := path.AtLength(path.Length() / 2)
+		// The height of the path where it reaches x = 2.
+		y, ok := path.InterpolateX(2)
+*/
+package spline
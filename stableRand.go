@@ -0,0 +1,56 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math/bits"
+)
+
+// splitMix64 is the SplitMix64 pseudo-random number generator. Unlike
+// math/rand, its stream is defined by this package and will never change:
+// each step adds 0x9E3779B97F4A7C15 to the state and returns the state mixed
+// by the SplitMix64 finalizer.
+type splitMix64 struct {
+	state uint64
+}
+
+// newSplitMix64 creates a generator whose initial state is the seed.
+func newSplitMix64(seed int64) *splitMix64 {
+	return &splitMix64{uint64(seed)}
+}
+
+// Uint64 returns the next value in the stream.
+func (s *splitMix64) Uint64() uint64 {
+	s.state += 0x9E3779B97F4A7C15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a value in [0, 1) from the high 53 bits of the next value.
+func (s *splitMix64) Float64() float64 {
+	return float64(int64(s.Uint64()>>11)) / (1 << 53)
+}
+
+// Intn returns a value in [0, n) from the high 64 bits of the product of the
+// next value and n. It consumes exactly one value of the stream.
+func (s *splitMix64) Intn(n int) int {
+	hi, _ := bits.Mul64(s.Uint64(), uint64(n))
+	return int(hi)
+}
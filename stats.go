@@ -0,0 +1,111 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"context"
+)
+
+// Stats summarizes noise sampled over a region, such as for normalizing the
+// noise into an image.
+type Stats struct {
+	// Count is the number of samples.
+	Count int
+	// Min and Max are the smallest and largest samples.
+	Min, Max float64
+	// Mean and Variance are the mean and population variance of the samples.
+	Mean, Variance float64
+	// Histogram counts the samples in bins of equal width spanning Min to
+	// Max. Samples equal to Max are counted in the last bin, and when every
+	// sample is equal they are all counted in the first bin.
+	Histogram []int
+}
+
+// SampleStats samples the noise over a region and returns its statistics with
+// a histogram of the given number of bins. The region is sampled like
+// WriteGreyImagePng: numberSamplesX by numberSamplesY points starting at
+// (minX, minY) and noiseSampleDelta apart.
+func SampleStats(noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, bins int) (*Stats, error) {
+	return SampleStatsContext(context.Background(), noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, 0, bins)
+}
+
+// SampleStatsContext is SampleStats sampling with the given number of worker
+// goroutines, stopping with the context's error if it is done first. A
+// non-positive number of workers uses GOMAXPROCS workers.
+func SampleStatsContext(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers, bins int) (*Stats, error) {
+	if numberSamplesX <= 0 || numberSamplesY <= 0 {
+		return nil, errInvalidDimensions
+	}
+	rows, err := sampleRows(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers)
+	if err != nil {
+		return nil, err
+	}
+	return newStats(rows, bins), nil
+}
+
+// Normalize maps the value onto zero to one, where Min is zero and Max is
+// one. Every value is zero when Min equals Max.
+func (s *Stats) Normalize(v float64) float64 {
+	if s.Max <= s.Min {
+		return 0
+	}
+	return (v - s.Min) / (s.Max - s.Min)
+}
+
+// newStats computes the statistics of rows of samples, which must not be
+// empty. The mean is found before the variance for accuracy.
+func newStats(rows [][]float64, bins int) *Stats {
+	s := &Stats{
+		Min: rows[0][0],
+		Max: rows[0][0],
+	}
+	sum := 0.0
+	for _, row := range rows {
+		for _, v := range row {
+			if v < s.Min {
+				s.Min = v
+			}
+			if v > s.Max {
+				s.Max = v
+			}
+			sum += v
+		}
+		s.Count += len(row)
+	}
+	s.Mean = sum / float64(s.Count)
+
+	if bins > 0 {
+		s.Histogram = make([]int, bins)
+	}
+	squares := 0.0
+	for _, row := range rows {
+		for _, v := range row {
+			d := v - s.Mean
+			squares += float64(d * d)
+			if bins > 0 {
+				bin := int(s.Normalize(v) * float64(bins))
+				if bin >= bins {
+					bin = bins - 1
+				}
+				s.Histogram[bin]++
+			}
+		}
+	}
+	s.Variance = squares / float64(s.Count)
+	return s
+}
@@ -0,0 +1,93 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSampleStatsConstant(t *testing.T) {
+	for _, c := range []float64{0, -0.75, 3} {
+		s, err := SampleStats(constantNoise(c), startCorner, startCorner, 7, 5, sampleStep, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Count != 35 || s.Min != c || s.Max != c || s.Mean != c || s.Variance != 0 {
+			t.Errorf("constant %v: got %+v", c, s)
+		}
+		if s.Histogram[0] != 35 {
+			t.Errorf("constant %v: histogram %v, want all samples in the first bin", c, s.Histogram)
+		}
+		if v := s.Normalize(c); v != 0 {
+			t.Errorf("constant %v: Normalize = %v, want 0", c, v)
+		}
+	}
+}
+
+func TestSampleStatsRows(t *testing.T) {
+	// Rows one through three, so the minimum is in the first row and
+	// later rows do not start from zero.
+	s, err := SampleStats(rowNoise{}, 0, 1, 2, 3, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Count != 6 || s.Min != 1 || s.Max != 3 || s.Mean != 2 {
+		t.Errorf("got %+v", s)
+	}
+	if math.Abs(s.Variance-2.0/3) > 1e-12 {
+		t.Errorf("variance = %v, want 2/3", s.Variance)
+	}
+	for i, count := range s.Histogram {
+		if count != 2 {
+			t.Errorf("histogram bin %d = %d, want 2", i, count)
+		}
+	}
+	if v := s.Normalize(2); v != 0.5 {
+		t.Errorf("Normalize(2) = %v, want 0.5", v)
+	}
+}
+
+func TestSampleStatsPerlin(t *testing.T) {
+	s, err := SampleStats(NewPerlin(seed), startCorner, startCorner, 100, 100, sampleStep, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, count := range s.Histogram {
+		total += count
+	}
+	if total != s.Count {
+		t.Errorf("histogram holds %d samples, want %d", total, s.Count)
+	}
+	if s.Min >= s.Mean || s.Mean >= s.Max || s.Variance <= 0 {
+		t.Errorf("got %+v", s)
+	}
+}
+
+func TestSampleStatsErrors(t *testing.T) {
+	if _, err := SampleStats(constantNoise(1), 0, 0, 0, 1, 1, 1); err == nil {
+		t.Error("no error for an empty region")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SampleStatsContext(ctx, constantNoise(1), 0, 0, 1, 1, 1, 1, 1); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
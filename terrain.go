@@ -0,0 +1,246 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Relief describes how terrain renderers light and color noise treated as a
+// heightfield.
+type Relief struct {
+	// Scale multiplies the height of the noise, which is otherwise in the
+	// same units as its coordinates. Zero leaves the height unscaled, as a
+	// scale of one does.
+	Scale float64
+	// Azimuth is the compass direction of the sun in degrees, clockwise from
+	// the top of the image.
+	Azimuth float64
+	// Altitude is the angle of the sun above the horizon in degrees.
+	Altitude float64
+	// Ramp colors the rendering. A nil Ramp renders in shades of grey.
+	Ramp *ColorRamp
+	// Normalizer maps the heights onto the Ramp for hillshades. A nil
+	// Normalizer stretches the heights like LatestVersion.
+	Normalizer Normalizer
+}
+
+// DefaultRelief returns the conventional lighting of shaded relief maps, with
+// the sun in the upper left at 45 degrees and no exaggeration of the height.
+func DefaultRelief() *Relief {
+	return &Relief{
+		Scale:    1,
+		Azimuth:  315,
+		Altitude: 45,
+	}
+}
+
+// scale returns the Scale of the Relief, with zero meaning one.
+func (r *Relief) scale() float64 {
+	if r.Scale == 0 {
+		return 1
+	}
+	return r.Scale
+}
+
+// NewHillshade renders the noise as shaded relief lit by the sun of the
+// Relief. The noise is sampled like WriteGreyImagePngNormalized, and a nil
+// Relief uses DefaultRelief. With a Ramp, each pixel is the color of its
+// height darkened by the shading.
+func NewHillshade(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, relief *Relief) (*image.NRGBA64, error) {
+	if relief == nil {
+		relief = DefaultRelief()
+	}
+	azimuth := relief.Azimuth * math.Pi / 180
+	altitude := relief.Altitude * math.Pi / 180
+	sunX := math.Sin(azimuth) * math.Cos(altitude)
+	sunY := math.Cos(azimuth) * math.Cos(altitude)
+	sunZ := math.Sin(altitude)
+	scale := relief.scale()
+
+	terrain, err := sampleTerrain(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers)
+	if err != nil {
+		return nil, err
+	}
+	normalizer := relief.Normalizer
+	if normalizer == nil {
+		heights := make([][]float64, len(terrain))
+		for y, row := range terrain {
+			heights[y] = make([]float64, numberSamplesX)
+			for x := range heights[y] {
+				heights[y][x] = row[3*x]
+			}
+		}
+		normalizer = newStats(heights, 0)
+	}
+
+	return renderTerrain(terrain, numberSamplesX, func(height, dx, dy float64) color.NRGBA64 {
+		nx, ny, nz := surfaceNormal(dx, dy, scale)
+		shade := clampUnit(nx*sunX + ny*sunY + nz*sunZ)
+		if relief.Ramp == nil {
+			return greyRelief(shade)
+		}
+		return shadeColor(relief.Ramp.At(clampUnit(normalizer.Normalize(height))), shade)
+	}), nil
+}
+
+// NewSlope renders the steepness of the noise, from flat in black to vertical
+// in white, or along the Ramp of the Relief. A nil Relief uses DefaultRelief.
+func NewSlope(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, relief *Relief) (*image.NRGBA64, error) {
+	if relief == nil {
+		relief = DefaultRelief()
+	}
+	scale := relief.scale()
+	terrain, err := sampleTerrain(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers)
+	if err != nil {
+		return nil, err
+	}
+	return renderTerrain(terrain, numberSamplesX, func(height, dx, dy float64) color.NRGBA64 {
+		slope := math.Atan(scale*math.Hypot(dx, dy)) / (math.Pi / 2)
+		return rampRelief(relief.Ramp, slope)
+	}), nil
+}
+
+// flatSlope is the steepness below which NewAspect treats the ground as flat,
+// which absorbs the rounding of finite difference gradients on plateaus.
+const flatSlope = 1e-9
+
+// flatColor is the color of flat ground in NewAspect, a grey that AspectRamp
+// never gives.
+var flatColor = color.NRGBA64{R: 32896, G: 32896, B: 32896, A: 65535}
+
+// NewAspect renders the compass direction that the slopes of the noise face,
+// clockwise from the top of the image, around the Ramp of the Relief as the
+// direction turns from zero to 360 degrees. A nil Ramp uses AspectRamp, and
+// other ramps should start and end with the same color to avoid a seam
+// through slopes facing north. Flat ground is a mid grey. A nil Relief uses
+// DefaultRelief.
+func NewAspect(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, relief *Relief) (*image.NRGBA64, error) {
+	if relief == nil {
+		relief = DefaultRelief()
+	}
+	ramp := relief.Ramp
+	if ramp == nil {
+		ramp = AspectRamp()
+	}
+	terrain, err := sampleTerrain(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers)
+	if err != nil {
+		return nil, err
+	}
+	return renderTerrain(terrain, numberSamplesX, func(height, dx, dy float64) color.NRGBA64 {
+		if math.Hypot(dx, dy) < flatSlope {
+			return flatColor
+		}
+		// The slope faces downhill, against the gradient.
+		aspect := math.Atan2(-dx, -dy) / (2 * math.Pi)
+		if aspect < 0 {
+			aspect++
+		}
+		return rampRelief(ramp, aspect)
+	}), nil
+}
+
+// WriteHillshadePng writes the shaded relief of NewHillshade out to a PNG
+// image.
+func WriteHillshadePng(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, relief *Relief) error {
+	img, err := NewHillshade(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, relief)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// WriteSlopePng writes the steepness of NewSlope out to a PNG image.
+func WriteSlopePng(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, relief *Relief) error {
+	img, err := NewSlope(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, relief)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// WriteAspectPng writes the slope directions of NewAspect out to a PNG image.
+func WriteAspectPng(ctx context.Context, w io.Writer, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int, relief *Relief) error {
+	img, err := NewAspect(ctx, noiser, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, relief)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// sampleTerrain samples the height and slopes of the noise at every point,
+// three values per point.
+func sampleTerrain(ctx context.Context, noiser Noiser, minX, minY float64, numberSamplesX, numberSamplesY int, noiseSampleDelta float64, workers int) ([][]float64, error) {
+	if numberSamplesX <= 0 || numberSamplesY <= 0 {
+		return nil, errInvalidDimensions
+	}
+	return sampleGrid(ctx, minX, minY, numberSamplesX, numberSamplesY, noiseSampleDelta, workers, 3, func(out []float64, x, y float64) {
+		out[0] = noiser.Noise(x, y)
+		out[1], out[2] = NoiseGradient(noiser, x, y)
+	})
+}
+
+// renderTerrain colors each sampled point with render, placing the first row
+// at the bottom of the image.
+func renderTerrain(terrain [][]float64, numberSamplesX int, render func(height, dx, dy float64) color.NRGBA64) *image.NRGBA64 {
+	numberSamplesY := len(terrain)
+	img := image.NewNRGBA64(image.Rect(0, 0, numberSamplesX, numberSamplesY))
+	for y, row := range terrain {
+		for x := 0; x < numberSamplesX; x++ {
+			img.SetNRGBA64(x, numberSamplesY-y-1, render(row[3*x], row[3*x+1], row[3*x+2]))
+		}
+	}
+	return img
+}
+
+// surfaceNormal returns the unit normal of a heightfield with the slopes,
+// with the height multiplied by scale.
+func surfaceNormal(dx, dy, scale float64) (x, y, z float64) {
+	x = -scale * dx
+	y = -scale * dy
+	length := math.Sqrt(x*x + y*y + 1)
+	return x / length, y / length, 1 / length
+}
+
+// rampRelief colors a value from zero to one along the ramp, or as a shade of
+// grey without one.
+func rampRelief(ramp *ColorRamp, v float64) color.NRGBA64 {
+	if ramp == nil {
+		return greyRelief(v)
+	}
+	return shadeColor(ramp.At(v), 1)
+}
+
+// greyRelief is the opaque shade of grey of a value from zero to one.
+func greyRelief(v float64) color.NRGBA64 {
+	grey := uint16(linearInterpolation(0, 65535, clampUnit(v)))
+	return color.NRGBA64{R: grey, G: grey, B: grey, A: 65535}
+}
+
+// shadeColor darkens the color by the shade from zero to one.
+func shadeColor(c color.NRGBA, shade float64) color.NRGBA64 {
+	channel := func(v uint8) uint16 {
+		return uint16(math.Round(float64(v) * 257 * shade))
+	}
+	return color.NRGBA64{R: channel(c.R), G: channel(c.G), B: channel(c.B), A: uint16(c.A) * 257}
+}
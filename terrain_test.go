@@ -0,0 +1,211 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// columnNoise is the x coordinate, so that it rises to the right.
+type columnNoise struct{}
+
+func (columnNoise) Noise(x, y float64) float64 {
+	return x
+}
+
+// nearShade reports whether the shades differ by at most one, allowing for
+// the rounding of finite differences.
+func nearShade(got, want uint16) bool {
+	return int(got)-int(want) <= 1 && int(want)-int(got) <= 1
+}
+
+func TestNewHillshade(t *testing.T) {
+	ctx := context.Background()
+	img, err := NewHillshade(ctx, constantNoise(1), 0, 0, 2, 2, 1, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.NRGBA64At(0, 0).R; got != 46340 {
+		t.Errorf("flat ground lit at 45 degrees = %d, want 46340", got)
+	}
+
+	tests := []struct {
+		azimuth float64
+		want    uint16
+	}{
+		{0, 0},
+		{90, 32767},
+		{180, 65535},
+	}
+	for _, test := range tests {
+		relief := &Relief{Scale: 1, Azimuth: test.azimuth, Altitude: 45}
+		img, err := NewHillshade(ctx, rowNoise{}, 0, 0, 2, 2, 1, 0, relief)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The ground rises up the image, so it faces the sun in the south.
+		if got := img.NRGBA64At(1, 1).R; !nearShade(got, test.want) {
+			t.Errorf("sun at %v degrees shades %d, want %d", test.azimuth, got, test.want)
+		}
+	}
+}
+
+func TestReliefZeroScale(t *testing.T) {
+	ctx := context.Background()
+	relief := &Relief{Azimuth: 180, Altitude: 45}
+	img, err := NewHillshade(ctx, rowNoise{}, 0, 0, 2, 2, 1, 0, relief)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.NRGBA64At(1, 1).R; !nearShade(got, 65535) {
+		t.Errorf("hillshade with a zero scale = %d, want 65535", got)
+	}
+	img, err = NewSlope(ctx, rowNoise{}, 0, 0, 1, 1, 1, 0, relief)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.NRGBA64At(0, 0).R; !nearShade(got, 32767) {
+		t.Errorf("slope with a zero scale = %d, want 32767", got)
+	}
+}
+
+func TestNewHillshadeRamp(t *testing.T) {
+	relief := &Relief{
+		Scale:      1,
+		Azimuth:    180,
+		Altitude:   45,
+		Ramp:       GrayscaleRamp(),
+		Normalizer: &FixedRange{Min: 0, Max: 2},
+	}
+	img, err := NewHillshade(context.Background(), rowNoise{}, 0, 0, 1, 3, 1, 0, relief)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fully lit, so the colors are those of the ramp at each height.
+	for i, want := range []uint16{0, 128 * 257, 65535} {
+		if got := img.NRGBA64At(0, 2-i); got.R != want || got.A != 65535 {
+			t.Errorf("row %d color = %v, want grey %d", i, got, want)
+		}
+	}
+}
+
+func TestNewSlope(t *testing.T) {
+	ctx := context.Background()
+	img, err := NewSlope(ctx, rowNoise{}, 0, 0, 1, 1, 1, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.NRGBA64At(0, 0).R; !nearShade(got, 32767) {
+		t.Errorf("45 degree slope = %d, want 32767", got)
+	}
+	img, err = NewSlope(ctx, constantNoise(5), 0, 0, 1, 1, 1, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.NRGBA64At(0, 0); got != (color.NRGBA64{0, 0, 0, 65535}) {
+		t.Errorf("flat slope = %v, want black", got)
+	}
+}
+
+// plateauNoise is flat apart from rounding, like a clamped noise, and has no
+// exact gradient.
+type plateauNoise struct{}
+
+func (plateauNoise) Noise(x, y float64) float64 {
+	return 0.1 + 1e-14*x
+}
+
+func TestNewAspect(t *testing.T) {
+	tests := []struct {
+		name   string
+		noiser Noiser
+		want   color.NRGBA64
+	}{
+		{"facing north", &rotatedNoise{}, color.NRGBA64{65535, 0, 0, 65535}},
+		{"facing south", rowNoise{}, color.NRGBA64{0, 65535, 65535, 65535}},
+		{"facing west", columnNoise{}, color.NRGBA64{32896, 0, 65535, 65535}},
+		{"flat", constantNoise(0.5), flatColor},
+		{"plateau", plateauNoise{}, flatColor},
+	}
+	for _, test := range tests {
+		img, err := NewAspect(context.Background(), test.noiser, 0, 0, 1, 1, 1, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := img.NRGBA64At(0, 0); got != test.want {
+			t.Errorf("%s: aspect = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestNewAspectHasNoSeam(t *testing.T) {
+	// Slopes facing just either side of north have nearly the same color.
+	east := &rotatedNoise{angle: 0.01}
+	west := &rotatedNoise{angle: -0.01}
+	colors := make([]color.NRGBA64, 2)
+	for i, n := range []Noiser{east, west} {
+		img, err := NewAspect(context.Background(), n, 0, 0, 1, 1, 1, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		colors[i] = img.NRGBA64At(0, 0)
+	}
+	for _, c := range colors {
+		if c.R < 60000 || c.G > 2000 || c.B > 2000 {
+			t.Errorf("slope facing nearly north = %v, want nearly red", c)
+		}
+	}
+}
+
+// rotatedNoise is a plane falling towards the compass direction angle, in
+// radians clockwise from north.
+type rotatedNoise struct {
+	angle float64
+}
+
+func (r *rotatedNoise) Noise(x, y float64) float64 {
+	return -(x*math.Sin(r.angle) + y*math.Cos(r.angle))
+}
+
+func TestWriteTerrainPngs(t *testing.T) {
+	writers := map[string]func(*bytes.Buffer) error{
+		"hillshade": func(b *bytes.Buffer) error {
+			return WriteHillshadePng(context.Background(), b, NewPerlin(seed), startCorner, startCorner, 8, 8, sampleStep, 0, nil)
+		},
+		"slope": func(b *bytes.Buffer) error {
+			return WriteSlopePng(context.Background(), b, NewPerlin(seed), startCorner, startCorner, 8, 8, sampleStep, 0, nil)
+		},
+		"aspect": func(b *bytes.Buffer) error {
+			return WriteAspectPng(context.Background(), b, NewPerlin(seed), startCorner, startCorner, 8, 8, sampleStep, 0, nil)
+		},
+	}
+	for name, write := range writers {
+		var buffer bytes.Buffer
+		if err := write(&buffer); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if _, err := png.Decode(&buffer); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+	}
+}
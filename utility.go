@@ -54,8 +54,12 @@ type point2D struct {
 }
 
 // DotFloat64 performs an inner product.
+//
+// The explicit conversions here and in the other interpolation helpers stop
+// the compiler from fusing multiplications and additions, which would change
+// results on some architectures.
 func (p point2D) DotFloat64(x, y float64) float64 {
-	return p.X*x + p.Y*y
+	return float64(p.X*x) + float64(p.Y*y)
 }
 
 // Dot performs an inner product.
@@ -65,7 +69,7 @@ func (p point2D) Dot(o point2D) float64 {
 
 // Scale applies a scalar to both x and y values of this point.
 func (p point2D) Scale(s float64) point2D {
-	return point2D{p.X * s, p.Y * s}
+	return point2D{float64(p.X * s), float64(p.Y * s)}
 }
 
 // Add adds a point to this one.
@@ -90,13 +94,29 @@ func intFloor(x float64) int {
 
 // fader is a second-derivative-continuous fading function for Perlin noise.
 func fader(t float64) float64 {
-	return t * t * t * (10 + t*(-15+t*6))
+	inner := -15 + float64(t*6)
+	return t * t * t * (10 + float64(t*inner))
 }
 
 // linearInterpolation performs linear interpolation using a fractional t value
 // in the range 0 <= t <= 1.
 func linearInterpolation(x0, x1, t float64) float64 {
-	return (1-t)*x0 + t*x1
+	return float64((1-t)*x0) + float64(t*x1)
+}
+
+// cubicCatmullRom evaluates the uniform Catmull-Rom spline through four values
+// spaced one unit apart, between the second and third values, using a
+// fractional t value in the range 0 <= t <= 1. Neighboring segments of the
+// spline share their first derivative where they meet.
+func cubicCatmullRom(p0, p1, p2, p3, t float64) float64 {
+	// The coefficients of t, t^2 and t^3, doubled.
+	linear := p2 - p0
+	quadratic := float64(2*p0) - float64(5*p1) + float64(4*p2) - p3
+	cubic := float64(3*(p1-p2)) + p3 - p0
+	// Horner's rule, rounding each product before it is added.
+	sum := quadratic + float64(t*cubic)
+	sum = linear + float64(t*sum)
+	return p1 + float64(0.5*t*sum)
 }
 
 // coordTransformToUnskew calculates the skew value for simplex noise when
@@ -111,19 +131,73 @@ func coordTransformToSkew(dims int) float64 {
 	return ((1 / math.Sqrt(float64(dims+1))) - 1) / float64(dims)
 }
 
-// intMod returns the mod value between two integers as an integer.
+// intMod returns the mod value between two integers as an integer. Like
+// math.Mod, the result has the sign of a.
 func intMod(a, b int) int {
-	return int(math.Mod(float64(a), float64(b)))
+	return a % b
 }
 
 // distance determines the distance between two points.
 func distance(x0, y0, x1, y1 float64) float64 {
 	dx := x1 - x0
 	dy := y1 - y0
-	return math.Sqrt(dx*dx + dy*dy)
+	return math.Sqrt(float64(dx*dx) + float64(dy*dy))
 }
 
 // distance0 determines the distance from the origin.
 func distance0(x, y float64) float64 {
 	return distance(0, 0, x, y)
 }
+
+// latticeStep advances a lattice coordinate, split into its cell and its
+// position relative to that cell, by delta. This avoids flooring the full
+// coordinate when stepping across a regular grid.
+func latticeStep(cell int, rel, delta float64) (int, float64) {
+	rel += delta
+	if rel >= 1 || rel < 0 {
+		whole := math.Floor(rel)
+		cell += int(whole)
+		rel -= whole
+	}
+	return cell, rel
+}
+
+// Float32Tolerance is the largest difference between the values of a single
+// precision generator and its double precision counterpart, when both are
+// given the same seed and the same float32 coordinates with magnitudes of at
+// most one thousand. Single precision loses accuracy further from the origin.
+const Float32Tolerance = 1e-5
+
+// point2D32 is a single precision two-dimensional point.
+type point2D32 struct {
+	X, Y float32
+}
+
+// DotFloat32 performs an inner product.
+func (p point2D32) DotFloat32(x, y float32) float32 {
+	return p.X*x + p.Y*y
+}
+
+// gradient2D32 is gradient2D in single precision.
+var gradient2D32 []point2D32 = func() []point2D32 {
+	g := make([]point2D32, len(gradient2D))
+	for i, p := range gradient2D {
+		g[i] = point2D32{float32(p.X), float32(p.Y)}
+	}
+	return g
+}()
+
+// intFloor32 is a helper for converting a float32 to an int after flooring.
+func intFloor32(x float32) int {
+	return int(math.Floor(float64(x)))
+}
+
+// fader32 is fader in single precision.
+func fader32(t float32) float32 {
+	return t * t * t * (10 + t*(-15+t*6))
+}
+
+// linearInterpolation32 is linearInterpolation in single precision.
+func linearInterpolation32(x0, x1, t float32) float32 {
+	return (1-t)*x0 + t*x1
+}
@@ -0,0 +1,203 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// rowNoise is the y coordinate, so that each image row has a single value.
+type rowNoise struct{}
+
+func (rowNoise) Noise(x, y float64) float64 {
+	return y
+}
+
+func TestVersion1IsFrozen(t *testing.T) {
+	perlin, err := NewPerlinVersion(42, Version1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "Perlin", perlin, []float64{
+		-0.24999999999999992, -0.1529128972764366, 0.14493896743257126, 3.3879446309883696e-05, 0.0013660290412560543,
+	})
+	simplex, err := NewSimplexVersion(42, Version1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "Simplex", simplex, []float64{
+		-0.005994051436466271, -0.00413668522027273, -0.003524981351926107, 0.0010193135932365731, -0.006822937333785136,
+	})
+	catmullRom, err := NewPerlinCatmullRomVersion(splineCacheSize, 42, Version1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "PerlinCatmullRom", catmullRom, []float64{
+		-0.2486154223789187, 0.02066423437264467, 0.04776774399776479, 0.10584857394971024, 0.001972780349262626,
+	})
+	catmullRom, err = NewPerlinCatmullRomVersion(16, 7, Version1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "PerlinCatmullRom with 16 points", catmullRom, []float64{
+		-0.3929937003786145, -0.23615676022027077, -0.21627271688455163, -0.23281895964649219, -0.0011724198932983728,
+	})
+}
+
+func TestVersion2IsFrozen(t *testing.T) {
+	perlin, err := NewPerlinVersion(42, Version2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "Perlin", perlin, []float64{
+		-0.04575317547305474, 0.3111599922119066, -0.03231315333749305, -0.12227210193082091, 0.0013660154014832786,
+	})
+	simplex, err := NewSimplexVersion(42, Version2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "Simplex", simplex, []float64{
+		-0.0059940514364662705, 0.008175815507908024, 0.0024114548140802806, 0.002799103347084278, -0.007202714934945445,
+	})
+	catmullRom, err := NewPerlinCatmullRomVersion(splineCacheSize, 42, Version2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "PerlinCatmullRom", catmullRom, []float64{
+		-0.1512356785104805, 0.3236183220043866, -0.12007712111247659, -0.1771290368057315, 0.0015468242179433878,
+	})
+}
+
+func TestUnknownVersion(t *testing.T) {
+	if _, err := NewPerlinVersion(seed, 0); err == nil {
+		t.Error("NewPerlinVersion accepted version 0")
+	}
+	if _, err := NewSimplexVersion(seed, LatestVersion+1); err == nil {
+		t.Error("NewSimplexVersion accepted a future version")
+	}
+	if _, err := NewPerlinCatmullRomVersion(splineCacheSize, seed, -1); err == nil {
+		t.Error("NewPerlinCatmullRomVersion accepted version -1")
+	}
+	var buffer bytes.Buffer
+	if err := WriteGreyImagePngVersion(&buffer, rowNoise{}, 0, 0, 1, 1, 1, 0); err == nil {
+		t.Error("WriteGreyImagePngVersion accepted version 0")
+	}
+}
+
+// greyRows writes rowNoise for rows one through three and returns the shade
+// of each row, from the lowest noise coordinate up.
+func greyRows(t *testing.T, version Version) []uint16 {
+	var buffer bytes.Buffer
+	if err := WriteGreyImagePngVersion(&buffer, rowNoise{}, 0, 1, 2, 3, 1, version); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gray := img.(*image.Gray16)
+	return []uint16{gray.Gray16At(0, 2).Y, gray.Gray16At(0, 1).Y, gray.Gray16At(0, 0).Y}
+}
+
+func TestWriteGreyImagePngVersions(t *testing.T) {
+	// Version1 starts later rows from zero, so the minimum is wrongly zero.
+	if got, want := greyRows(t, Version1), []uint16{21845, 43690, 65535}; !equalShades(got, want) {
+		t.Errorf("Version1 shades = %v, want %v", got, want)
+	}
+	if got, want := greyRows(t, Version2), []uint16{0, 32767, 65535}; !equalShades(got, want) {
+		t.Errorf("Version2 shades = %v, want %v", got, want)
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteGreyImagePngVersion(&buffer, constantNoise(0.5), 0, 0, 2, 2, 1, Version2); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shade := img.(*image.Gray16).Gray16At(1, 1).Y; shade != 0 {
+		t.Errorf("constant noise shade = %d, want 0", shade)
+	}
+}
+
+// version1GreyImagePng writes an image the way WriteGreyImagePng did in the
+// first release, one row at a time.
+func version1GreyImagePng(w io.Writer, noiser Noiser, minX, minY, numberSamplesX, numberSamplesY int, noiseSampleDelta float64) error {
+	grays := make([][]float64, numberSamplesY)
+	noiseY := float64(minY)
+	for y := range grays {
+		grays[y] = make([]float64, numberSamplesX)
+		noiseX := float64(minX)
+		for x := range grays[y] {
+			grays[y][x] = noiser.Noise(noiseX, noiseY)
+			noiseX += noiseSampleDelta
+		}
+		noiseY += noiseSampleDelta
+	}
+	minValue, maxValue := version1Range(grays)
+	img := image.NewGray16(image.Rect(0, 0, numberSamplesX, numberSamplesY))
+	for y := range grays {
+		for x := range grays[y] {
+			frac := (grays[y][x] - minValue) / (maxValue - minValue)
+			img.SetGray16(x, numberSamplesY-y-1, color.Gray16{uint16(linearInterpolation(0, 65535, frac))})
+		}
+	}
+	return png.Encode(w, img)
+}
+
+func TestWriteGreyImagePngVersion1IsFrozen(t *testing.T) {
+	tests := []struct {
+		name   string
+		noiser Noiser
+		delta  float64
+	}{
+		{"perlin", NewPerlin(seed), sampleStep},
+		{"constant", constantNoise(0.5), 1},
+		{"rows", rowNoise{}, 0.1},
+	}
+	for _, test := range tests {
+		var got, want bytes.Buffer
+		if err := WriteGreyImagePng(&got, test.noiser, -3, 2, 30, 20, test.delta); err != nil {
+			t.Fatal(err)
+		}
+		if err := version1GreyImagePng(&want, test.noiser, -3, 2, 30, 20, test.delta); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("%s: image differs from the first release", test.name)
+		}
+	}
+}
+
+func equalShades(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
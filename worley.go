@@ -0,0 +1,73 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"math"
+)
+
+var _ Noiser = &Worley{}
+
+// Worley creates cellular noise, also known as Voronoi noise. Every unit cell
+// of the lattice holds one feature point, and the noise grows with the distance
+// to the nearest feature point. It looks like cells or cracked stone rather
+// than the smooth hills of Perlin and simplex noise.
+type Worley struct {
+	seed uint64
+}
+
+// NewWorley returns a new source of cellular noise. The feature points are
+// placed by hashing each lattice cell with the seed, so the noise never
+// repeats and needs no permutation table.
+func NewWorley(seed int64) *Worley {
+	return &Worley{
+		seed: uint64(seed),
+	}
+}
+
+// Noise returns the distance from the point to the nearest feature point,
+// scaled so that the result is in the range [-1, 1]. It is -1 on a feature
+// point.
+func (w *Worley) Noise(x, y float64) float64 {
+	x0 := intFloor(x)
+	y0 := intFloor(y)
+	nearest := math.Inf(1)
+	// Searching the 3x3 block of cells around the point is the usual
+	// approximation. It can miss a nearer feature point two cells away when
+	// those in the block lie far off, which rarely shows.
+	for j := y0 - 1; j <= y0+1; j++ {
+		for i := x0 - 1; i <= x0+1; i++ {
+			fx, fy := w.featurePoint(i, j)
+			dx := fx - x
+			dy := fy - y
+			if d := float64(dx*dx) + float64(dy*dy); d < nearest {
+				nearest = d
+			}
+		}
+	}
+	return float64(math.Sqrt(nearest)*math.Sqrt2) - 1
+}
+
+// featurePoint returns the feature point inside the lattice cell whose lower
+// left corner is (x, y). The compiler may fuse the divisions by 2^32 with the
+// additions, but scaling by a power of two is exact, so the values do not
+// change.
+func (w *Worley) featurePoint(x, y int) (float64, float64) {
+	h := hashPoint(w.seed, x, y)
+	return float64(x) + float64(h>>32)/(1<<32), float64(y) + float64(uint32(h))/(1<<32)
+}
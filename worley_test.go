@@ -0,0 +1,57 @@
+/*
+	This file is part of noise.
+
+	noise is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	noise is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with noise.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package noise
+
+import (
+	"testing"
+)
+
+func TestWorleyRange(t *testing.T) {
+	w := NewWorley(seed)
+	xs, ys := batchPoints(imageDimension)
+	for i := range xs {
+		if v := w.Noise(xs[i], ys[i]); v < -1 || v > 1 {
+			t.Fatalf("Noise(%v, %v) = %v, want a value in [-1, 1]", xs[i], ys[i], v)
+		}
+	}
+}
+
+func TestWorleyFeaturePoints(t *testing.T) {
+	w := NewWorley(seed)
+	for y := -5; y <= 5; y++ {
+		for x := -5; x <= 5; x++ {
+			fx, fy := w.featurePoint(x, y)
+			if fx < float64(x) || fx >= float64(x+1) || fy < float64(y) || fy >= float64(y+1) {
+				t.Fatalf("feature point (%v, %v) is outside cell %d, %d", fx, fy, x, y)
+			}
+			if v := w.Noise(fx, fy); v != -1 {
+				t.Errorf("Noise(%v, %v) = %v at a feature point, want -1", fx, fy, v)
+			}
+		}
+	}
+}
+
+func TestWorleySeeds(t *testing.T) {
+	a, b := NewWorley(1), NewWorley(2)
+	if a.Noise(0.5, 0.5) == b.Noise(0.5, 0.5) && a.Noise(3.7, -2.1) == b.Noise(3.7, -2.1) {
+		t.Error("different seeds give the same noise")
+	}
+	if got, want := NewWorley(1).Noise(3.7, -2.1), a.Noise(3.7, -2.1); got != want {
+		t.Errorf("same seed gives %v and %v", got, want)
+	}
+}